@@ -1,33 +1,179 @@
 package cmd
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/fatkulnurk/gostarter/cmd/http"
+	"github.com/fatkulnurk/gostarter/cmd/kernel"
 	"github.com/fatkulnurk/gostarter/cmd/scheduler"
 	"github.com/fatkulnurk/gostarter/cmd/worker"
+	"github.com/fatkulnurk/gostarter/pkg/cli"
 	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
 )
 
-func ServeApp(svc string, cfg *config.Config) {
-	switch svc {
-	case "http":
+// Execute runs the command line against the application wiring and returns the process exit code
+// The configuration is loaded once the command line is parsed, so global flags can override it
+func Execute(ctx context.Context, name string, args []string) int {
+	a := &app{opts: config.Options{
+		Environment: os.Getenv("environment"),
+		Flags:       make(map[string]string),
+	}}
+	defer a.close()
+
+	root := &cli.Command{
+		Name:  name,
+		Short: "Run the application services and operations",
+	}
+	root.Flags().StringVar(&a.opts.Environment, "env", a.opts.Environment, "environment, selects the .env.<environment> file (default $environment or development)")
+	root.Flags().StringVar(&a.opts.File, "config", "", "YAML, JSON or TOML config file (default $CONFIG_FILE)")
+	root.Flags().Func("set", "override a config value, e.g. -set DB_HOST=localhost (repeatable)", func(kv string) error {
+		return config.ParseFlag(a.opts.Flags, kv)
+	})
+	root.Add(
+		serveCommand(a),
+		migrateCommand(a),
+		taskCommand(a),
+		scheduleCommand(a),
+		configCommand(a),
+		routesCommand(a),
+		moduleCommand(a),
+	)
+	for _, cmd := range kernel.ModuleCommands() {
+		// a module can't shadow a built-in command
+		if root.Find(cmd.Name) == nil {
+			root.Add(a.deferToKernel(cmd, []string{cmd.Name}))
+		}
+	}
+
+	return cli.Execute(ctx, root, args)
+}
+
+// ServeApp runs a single service mode, "all" or a comma-separated combination in one process
+func ServeApp(svc string, cfg *config.Config) error {
+	selected, err := ParseServices(svc)
+	if err != nil {
+		return cli.Usagef("%v", err)
+	}
+
+	if len(selected) > 1 {
+		fmt.Printf("Running %s in one process...\n", strings.Join(selected, ", "))
+		return ServeCombined(selected, cfg)
+	}
+
+	switch selected[0] {
+	case http.Component:
 		fmt.Println("Running in HTTP server mode...")
-		http.Serve(cfg)
-	case "worker":
+		return http.Serve(cfg)
+	case worker.Component:
 		fmt.Println("Running in background worker mode...")
-		worker.Serve(cfg)
-	case "scheduler":
-		fmt.Println("Running in scheduler mode...")
-		scheduler.Serve(cfg)
+		return worker.Serve(cfg)
 	default:
-		_, err := fmt.Fprintf(os.Stderr, "Error: invalid --svc value: %s\n", svc)
+		fmt.Println("Running in scheduler mode...")
+		return scheduler.Serve(cfg)
+	}
+}
+
+// app lazily loads the config and boots the kernel for commands, and closes the kernel once the command is done
+type app struct {
+	opts   config.Options
+	cfg    *config.Config
+	kernel *kernel.Kernel
+	logs   io.Closer
+}
+
+// config loads the configuration from the sources selected by the global flags
+func (a *app) config() (*config.Config, error) {
+	if a.cfg != nil {
+		return a.cfg, nil
+	}
+
+	cfg, err := config.Load(a.opts)
+	if err != nil {
+		return nil, err
+	}
+	if a.logs, err = initLogging(cfg.Logging, cfg.Metrics.Enabled); err != nil {
+		return nil, err
+	}
+	a.cfg = cfg
+	return cfg, nil
+}
+
+// boot creates the kernel and registers the modules on the given delivery
+func (a *app) boot(delivery *infrastructure.Delivery) (*kernel.Kernel, error) {
+	if a.kernel != nil {
+		return a.kernel, nil
+	}
+
+	cfg, err := a.config()
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := kernel.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.kernel = k
+
+	k.Output = io.Discard
+	if err := k.Boot(delivery); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (a *app) close() {
+	if a.kernel != nil {
+		_ = a.kernel.Close(context.Background())
+	}
+	if a.logs != nil {
+		_ = a.logs.Close()
+	}
+}
+
+// deferToKernel makes a module command, resolved without adapters, boot the kernel when it runs
+// and run the command at the same path of the booted modules with the flags set on the command line
+func (a *app) deferToKernel(cmd *cli.Command, path []string) *cli.Command {
+	for _, sub := range cmd.Subcommands {
+		a.deferToKernel(sub, append(slices.Clone(path), sub.Name))
+	}
+	if cmd.Run == nil {
+		return cmd
+	}
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		k, err := a.boot(&infrastructure.Delivery{})
 		if err != nil {
-			return
+			return err
+		}
+
+		booted := &cli.Command{Subcommands: k.Commands()}
+		for _, name := range path {
+			if booted = booted.Find(name); booted == nil {
+				return fmt.Errorf("module command %q is unavailable", strings.Join(path, " "))
+			}
+		}
+		if booted.Run == nil {
+			return fmt.Errorf("module command %q is unavailable", strings.Join(path, " "))
+		}
+
+		var flagErr error
+		cmd.Flags().Visit(func(f *flag.Flag) {
+			if err := booted.Flags().Set(f.Name, f.Value.String()); err != nil && flagErr == nil {
+				flagErr = cli.Usagef("%v", err)
+			}
+		})
+		if flagErr != nil {
+			return flagErr
 		}
-		flag.Usage()
-		os.Exit(1)
+		return booted.Run(ctx, args)
 	}
+	return cmd
 }
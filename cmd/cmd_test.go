@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/cmd/kernel"
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/pkg/module"
+)
+
+func TestExecuteUnknownCommand(t *testing.T) {
+	// nothing listens on this port, a command that connects would fail with ExitError
+	t.Setenv("DB_PORT", "1")
+
+	if code := Execute(context.Background(), "gostarter", []string{"mirgate"}); code != cli.ExitUsage {
+		t.Errorf("Expected exit code %d for a typo, got %d", cli.ExitUsage, code)
+	}
+}
+
+// reportModule contributes "report export -format <format>" and records the format it ran with
+type reportModule struct {
+	ran *string
+}
+
+func (m reportModule) GetInfo() *module.Module {
+	return &module.Module{Name: "Report", Prefix: "report"}
+}
+func (reportModule) RegisterHTTP()     {}
+func (reportModule) RegisterTask()     {}
+func (reportModule) RegisterSchedule() {}
+
+func (m reportModule) Commands() []*cli.Command {
+	export := &cli.Command{Name: "export"}
+	format := export.Flags().String("format", "csv", "output format")
+	export.Run = func(ctx context.Context, args []string) error {
+		*m.ran = *format
+		return nil
+	}
+	return []*cli.Command{export}
+}
+
+func TestDeferToKernel(t *testing.T) {
+	var static, booted string
+	staticRegistry := module.NewRegistry()
+	staticRegistry.Add(reportModule{ran: &static})
+	bootedRegistry := module.NewRegistry()
+	bootedRegistry.Add(reportModule{ran: &booted})
+
+	// a booted kernel, so boot does not connect
+	a := &app{kernel: &kernel.Kernel{Registry: bootedRegistry}}
+	root := &cli.Command{Name: "gostarter"}
+	for _, cmd := range (&kernel.Kernel{Registry: staticRegistry}).Commands() {
+		root.Add(a.deferToKernel(cmd, []string{cmd.Name}))
+	}
+
+	if code := cli.Execute(context.Background(), root, []string{"report", "export", "-format", "json"}); code != cli.ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitOK, code)
+	}
+	if static != "" || booted != "json" {
+		t.Errorf("Expected the booted command to run with -format json, got static %q and booted %q", static, booted)
+	}
+}
+
+func TestMigrateDirFlag(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// nothing listens on this port, so a parsed command fails to connect instead of failing to parse
+	t.Setenv("DB_PORT", "1")
+
+	for _, args := range [][]string{
+		{"migrate", "-dir", "db"},
+		{"migrate", "-dir", "db", "up"},
+		{"migrate", "up", "-dir", "db"},
+		{"migrate", "status", "-dir", "db"},
+	} {
+		if code := Execute(context.Background(), "gostarter", args); code != cli.ExitError {
+			t.Errorf("Expected %v to parse and fail to connect with exit code %d, got %d", args, cli.ExitError, code)
+		}
+	}
+}
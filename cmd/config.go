@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+)
+
+func configCommand(a *app) *cli.Command {
+	printConfig := &cli.Command{
+		Name:  "print",
+		Short: "Print the effective configuration and the source of every value",
+	}
+	redacted := printConfig.Flags().Bool("redacted", true, "mask secret values, use -redacted=false to reveal them")
+	format := printConfig.Flags().String("format", "text", "output format: text or json")
+
+	printConfig.Run = func(ctx context.Context, args []string) error {
+		cfg, err := a.config()
+		if err != nil {
+			return err
+		}
+		entries := cfg.Describe(*redacted)
+
+		switch *format {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(entries)
+		case "text":
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+			for _, e := range entries {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Key, e.Value, e.Source)
+			}
+			return tw.Flush()
+		default:
+			return cli.Usagef("invalid format %q, must be text or json", *format)
+		}
+	}
+
+	return &cli.Command{
+		Name:        "config",
+		Short:       "Inspect the configuration",
+		Subcommands: []*cli.Command{printConfig},
+	}
+}
@@ -2,99 +2,82 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"reflect"
 
+	"github.com/fatkulnurk/gostarter/cmd/kernel"
 	"github.com/fatkulnurk/gostarter/pkg/config"
-	"github.com/fatkulnurk/gostarter/pkg/module"
+	"github.com/fatkulnurk/gostarter/pkg/health"
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/metrics"
+	"github.com/fatkulnurk/gostarter/pkg/shutdown"
+	"github.com/fatkulnurk/gostarter/shared/constant"
 	"github.com/fatkulnurk/gostarter/shared/infrastructure"
-
 	"github.com/fatkulnurk/gostarter/shared/middleware"
 
-	"github.com/fatkulnurk/gostarter/internal/example"
-	"github.com/fatkulnurk/gostarter/pkg/db"
-	pkgqueue "github.com/fatkulnurk/gostarter/pkg/queue"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	gofibermiddlewarerecover "github.com/gofiber/fiber/v2/middleware/recover"
 )
 
-func Serve(cfg *config.Config) {
-	// adapter, only register what you need
-	adapter := func(cfg *config.Config) *infrastructure.Adapter {
-		mysql, err := db.NewMySQL(cfg.Database)
-		if err != nil {
-			panic(err)
-		}
-
-		redis, err := db.NewRedis(cfg.Redis)
-		if err != nil {
-			panic(err)
-		}
-
-		asynqClient, err := pkgqueue.NewAsynqClient(cfg.Queue, redis)
-		if err != nil {
-			panic(err)
-		}
-		queue := pkgqueue.NewAsynqQueue(asynqClient)
+// Component is the name of the http delivery in service mode lists and failure policies
+const Component = "http"
 
-		return &infrastructure.Adapter{
-			DB: &infrastructure.DatabaseConnection{
-				Sql:   mysql,
-				Redis: redis,
-			},
-			Queue: &queue,
-		}
-	}(cfg)
+// Serve runs the HTTP server until a shutdown signal or a failure, and returns the failure
+func Serve(cfg *config.Config) error {
+	k, err := kernel.New(cfg)
+	if err != nil {
+		return err
+	}
+	sd := shutdown.New()
+	k.RegisterShutdown(sd, cfg.DeliveryHttp.ShutdownTimeout)
 
 	// delivery, only register what you need
-	delivery := func(cfg *config.Config) *infrastructure.Delivery {
-		return &infrastructure.Delivery{
-			HTTP: initHttp(cfg),
-		}
-	}(cfg)
-
-	// Register modules
-	func() {
-		var modules []module.IModule
-		modules = append(modules, example.New(adapter, delivery))
+	delivery := &infrastructure.Delivery{
+		HTTP: NewApp(cfg, k.Watcher),
+	}
+	if err := k.Boot(delivery); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
+	if err := k.Start(context.Background()); err != nil {
+		return errors.Join(fmt.Errorf("could not start modules: %w", err), sd.Shutdown(context.Background()))
+	}
 
-		fmt.Printf("-------Register mdl------\n")
-		for idx, mdl := range modules {
-			fmt.Printf("number: %d\n", idx+1)
-			fmt.Printf("Registering mdl: %s\n", mdl.GetInfo().Name)
-			fmt.Printf("Prefix: %s\n", mdl.GetInfo().Prefix)
-			mdl.RegisterHTTP()
-			fmt.Printf("-------------------------\n")
-		}
-	}()
+	Start(cfg, delivery, sd)
+	if err := k.ServeAdmin(sd, cfg.DeliveryHttp.ShutdownTimeout); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
 
-	// Create a channel to listen for interrupt signals
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	// Wait for interrupt signal
+	waitErr := sd.Wait(context.Background())
+	fmt.Println("Shutting down gracefully...")
+	return errors.Join(waitErr, sd.Shutdown(context.Background()))
+}
 
-	// Start server in a goroutine
+// Start runs the HTTP server in the background and registers its drain on the coordinator
+// A server that fails to listen is reported to the coordinator as a failure of the http component
+func Start(cfg *config.Config, delivery *infrastructure.Delivery, sd *shutdown.Coordinator) {
 	go func() {
-		if err := delivery.HTTP.Listen(":8080"); err != nil {
-			fmt.Printf("Server error: %v\n", err)
+		if err := Listen(delivery.HTTP, cfg.DeliveryHttp); err != nil {
+			sd.Fail(Component, err)
 		}
 	}()
 
-	// Wait for interrupt signal
-	<-c
-	fmt.Println("Shutting down gracefully...")
-
-	// Shutdown with 5 second timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := delivery.HTTP.ShutdownWithContext(ctx); err != nil {
-		fmt.Printf("Server shutdown error: %v\n", err)
+	// a closed unix listener removes its socket file, this also removes it when the server never got to close it
+	// hooks run in reverse order, so this runs once the server stopped
+	if cfg.DeliveryHttp.Socket != "" {
+		sd.Register("http socket", cfg.DeliveryHttp.ShutdownTimeout, func(ctx context.Context) error {
+			return RemoveSocket(cfg.DeliveryHttp.Socket)
+		})
 	}
+	// stop accepting connections and wait for in-flight requests
+	sd.Register("http server", cfg.DeliveryHttp.ShutdownTimeout, delivery.HTTP.ShutdownWithContext)
 }
 
-func initHttp(cfg *config.Config) *fiber.App {
+// NewApp creates the fiber app with the global middlewares, the /ping, liveness and readiness probes
+// and the metrics endpoint. The access log sampling follows the DeliveryHttp changes of watcher, nil keeps cfg
+func NewApp(cfg *config.Config, watcher *config.Watcher) *fiber.App {
 	app := fiber.New(fiber.Config{
 		Prefork:       cfg.DeliveryHttp.Prefork,
 		CaseSensitive: cfg.DeliveryHttp.CaseSensitive,
@@ -102,12 +85,55 @@ func initHttp(cfg *config.Config) *fiber.App {
 		ServerHeader:  cfg.DeliveryHttp.ServerHeader,
 		AppName:       cfg.App.Name,
 		BodyLimit:     cfg.DeliveryHttp.BodyLimit,
+		ReadTimeout:   cfg.DeliveryHttp.ReadTimeout,
+		WriteTimeout:  cfg.DeliveryHttp.WriteTimeout,
+		IdleTimeout:   cfg.DeliveryHttp.IdleTimeout,
+		// internal error details are only shown to developers
+		ErrorHandler: middleware.ErrorHandler(cfg.DeliveryHttp.ErrorFormat, cfg.App.Environment == constant.EnvironmentDevelopment),
 	})
+	if cfg.Metrics.Enabled {
+		// first, so the latency covers every middleware and the status is the rendered one
+		app.Use(middleware.MetricsMiddleware(metrics.Default()))
+	}
+	if cfg.Tracing.Enabled {
+		// outside the recover, so a recovered panic marks the span as failed
+		app.Use(middleware.TracingMiddleware())
+	}
 	app.Use(gofibermiddlewarerecover.New())
-	app.Use(middleware.LoggingMiddleware())
+	app.Use(middleware.RequestIDMiddleware())
+	accessLog := middleware.NewAccessLog(middleware.AccessLogConfig{
+		Routes: cfg.DeliveryHttp.AccessLogRoutes,
+		Status: cfg.DeliveryHttp.AccessLogStatus,
+	})
+	app.Use(accessLog.Handler())
+	if watcher != nil {
+		watcher.Subscribe("DeliveryHttp", func(old, new *config.Config) {
+			reloadAccessLog(accessLog, old.DeliveryHttp, new.DeliveryHttp)
+		})
+	}
 	app.Get("/ping", func(c *fiber.Ctx) error {
 		return c.JSON("pong")
 	})
+	app.Get(cfg.Health.LivenessPath, adaptor.HTTPHandler(health.Default().LivenessHandler()))
+	app.Get(cfg.Health.ReadinessPath, adaptor.HTTPHandler(health.Default().ReadinessHandler()))
+	if cfg.Metrics.Enabled {
+		if err := metrics.Default().RegisterLogDrops("access_log", accessLog); err != nil {
+			logging.Warning(context.Background(), "Failed to export the dropped access log entries", logging.NewField("error", err.Error()))
+		}
+		app.Get(cfg.Metrics.Path, adaptor.HTTPHandler(metrics.Default().Handler()))
+	}
 
 	return app
 }
+
+// reloadAccessLog applies new access log sampling, the other HTTP settings take effect on restart
+func reloadAccessLog(accessLog *middleware.AccessLog, old, new *config.DeliveryHttp) {
+	accessLog.SetConfig(middleware.AccessLogConfig{Routes: new.AccessLogRoutes, Status: new.AccessLogStatus})
+
+	restart := *old
+	restart.AccessLogRoutes = new.AccessLogRoutes
+	restart.AccessLogStatus = new.AccessLogStatus
+	if !reflect.DeepEqual(&restart, new) {
+		logging.Warning(context.Background(), "HTTP settings other than the access log sampling change on restart")
+	}
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/tracing/tracingtest"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestNewAppTracesPanics(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("TRACING_ENABLED", "true")
+	t.Setenv("METRICS_ENABLED", "false")
+	cfg, err := config.Load(config.Options{})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	spans := tracingtest.Capture(t)
+
+	app := NewApp(cfg, nil)
+	app.Get("/panic", func(c *fiber.Ctx) error { panic("boom") })
+	res, err := app.Test(httptest.NewRequest("GET", "/panic", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("Expected a recovered panic to answer 500, got %d", res.StatusCode)
+	}
+
+	span := tracingtest.Span(t, spans, "GET /panic")
+	if span.Status.Code != codes.Error || len(span.Events) == 0 {
+		t.Errorf("Expected the span to record the panic, got status %s and %d events", span.Status.Code, len(span.Events))
+	}
+}
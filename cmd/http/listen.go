@@ -0,0 +1,118 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/fatkulnurk/gostarter/pkg/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Addr returns the host:port the server listens on when no Unix socket is configured
+func Addr(cfg *config.DeliveryHttp) string {
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+}
+
+// Listen serves the app on the configured Unix socket or host and port, over TLS when a certificate is set
+// With prefork enabled fiber has to own the socket, so the address is handed to fiber instead
+func Listen(app *fiber.App, cfg *config.DeliveryHttp) error {
+	if cfg.Prefork {
+		switch {
+		case cfg.TLSClientCAFile != "":
+			return app.ListenMutualTLS(Addr(cfg), cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+		case cfg.TLSCertFile != "":
+			return app.ListenTLS(Addr(cfg), cfg.TLSCertFile, cfg.TLSKeyFile)
+		default:
+			return app.Listen(Addr(cfg))
+		}
+	}
+
+	ln, err := NewListener(cfg)
+	if err != nil {
+		return err
+	}
+	return app.Listener(ln)
+}
+
+// NewListener opens the configured Unix socket or TCP address and wraps it in TLS when a certificate is set
+func NewListener(cfg *config.DeliveryHttp) (net.Listener, error) {
+	var tlsConfig *tls.Config
+	if cfg.TLSCertFile != "" {
+		var err error
+		if tlsConfig, err = TLSConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var ln net.Listener
+	var err error
+	if cfg.Socket != "" {
+		// a socket file left behind by a previous run would make listen fail
+		if err := RemoveSocket(cfg.Socket); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+		ln, err = net.Listen("unix", cfg.Socket)
+	} else {
+		ln, err = net.Listen("tcp", Addr(cfg))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		return tls.NewListener(ln, tlsConfig), nil
+	}
+	return ln, nil
+}
+
+// RemoveSocket removes the socket file at path, a missing file is not an error
+// Anything other than a socket is left alone and reported, so a mistyped HTTP_SOCKET can't delete a file
+func RemoveSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// TLSConfig loads the server certificate and, when a client CA is set, requires verified client certificates
+func TLSConfig(cfg *config.DeliveryHttp) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load tls certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read tls client ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls client ca %s contains no certificate", cfg.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/shutdown"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testCert is a certificate and key generated for a test, written as PEM files
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	tls      tls.Certificate
+	certFile string
+	keyFile  string
+}
+
+// newTestCert creates a certificate for 127.0.0.1 signed by parent, or a self-signed CA when parent is nil
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	c := &testCert{
+		cert:     cert,
+		key:      key,
+		tls:      pair,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	if err := os.WriteFile(c.certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// serve starts a fiber app answering /ping on a listener built from cfg and returns its address
+func serve(t *testing.T, cfg *config.DeliveryHttp) net.Addr {
+	t.Helper()
+
+	ln, err := NewListener(cfg)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendString("pong")
+	})
+	go func() {
+		_ = app.Listener(ln)
+	}()
+	t.Cleanup(func() {
+		_ = app.Shutdown()
+	})
+	return ln.Addr()
+}
+
+func get(client *nethttp.Client, url string) (string, error) {
+	res, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	return string(body), err
+}
+
+func TestListenTLS(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	server := newTestCert(t, "server", ca)
+	addr := serve(t, &config.DeliveryHttp{Host: "127.0.0.1", TLSCertFile: server.certFile, TLSKeyFile: server.keyFile})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := &nethttp.Client{Transport: &nethttp.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+	body, err := get(client, "https://"+addr.String()+"/ping")
+	if err != nil {
+		t.Fatalf("Expected a TLS request to succeed, got %v", err)
+	}
+	if body != "pong" {
+		t.Errorf("Expected pong, got %s", body)
+	}
+}
+
+func TestListenMutualTLS(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	server := newTestCert(t, "server", ca)
+	clientCert := newTestCert(t, "client", ca)
+	addr := serve(t, &config.DeliveryHttp{
+		Host:            "127.0.0.1",
+		TLSCertFile:     server.certFile,
+		TLSKeyFile:      server.keyFile,
+		TLSClientCAFile: ca.certFile,
+	})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	url := "https://" + addr.String() + "/ping"
+
+	anonymous := &nethttp.Client{Transport: &nethttp.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	if _, err := get(anonymous, url); err == nil {
+		t.Error("Expected a request without a client certificate to be rejected")
+	}
+
+	authenticated := &nethttp.Client{Transport: &nethttp.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{clientCert.tls},
+	}}}
+	if body, err := get(authenticated, url); err != nil || body != "pong" {
+		t.Errorf("Expected pong with a client certificate, got %q, %v", body, err)
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "http.sock")
+	// a stale socket file must not prevent listening
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	_ = stale.Close()
+	serve(t, &config.DeliveryHttp{Socket: socket})
+
+	client := &nethttp.Client{Transport: &nethttp.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	body, err := get(client, "http://unix/ping")
+	if err != nil {
+		t.Fatalf("Expected a request over the socket to succeed, got %v", err)
+	}
+	if body != "pong" {
+		t.Errorf("Expected pong, got %s", body)
+	}
+}
+
+func TestListenKeepsFileAtSocketPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewListener(&config.DeliveryHttp{Socket: path}); err == nil {
+		t.Error("Expected listening on a path holding a regular file to fail")
+	}
+	if err := RemoveSocket(path); err == nil {
+		t.Error("Expected RemoveSocket to refuse a regular file")
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != "data" {
+		t.Errorf("Expected the file to be kept, got %q, %v", content, err)
+	}
+}
+
+func TestStartRemovesSocketOnShutdown(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "http.sock")
+	cfg := &config.Config{DeliveryHttp: &config.DeliveryHttp{Socket: socket, ShutdownTimeout: time.Second}}
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	sd := shutdown.New()
+	Start(cfg, &infrastructure.Delivery{HTTP: app}, sd)
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(socket); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the server to listen on the socket")
+		}
+	}
+
+	if err := sd.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shutdown: %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket file to be removed on shutdown, got %v", err)
+	}
+}
+
+func TestTLSConfigRejectsEmptyClientCA(t *testing.T) {
+	server := newTestCert(t, "server", nil)
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := TLSConfig(&config.DeliveryHttp{TLSCertFile: server.certFile, TLSKeyFile: server.keyFile, TLSClientCAFile: empty})
+	if err == nil {
+		t.Fatal("Expected an error for a client CA without certificates")
+	}
+}
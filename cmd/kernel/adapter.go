@@ -0,0 +1,75 @@
+package kernel
+
+import (
+	"fmt"
+
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/db"
+	"github.com/fatkulnurk/gostarter/pkg/health"
+	pkgqueue "github.com/fatkulnurk/gostarter/pkg/queue"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+	"github.com/hibiken/asynq"
+)
+
+// closer releases an adapter resource on shutdown
+type closer struct {
+	name  string
+	close func() error
+}
+
+// initAdapter builds the infrastructure adapter shared by every service mode
+// register new adapters here, only register what you need
+// every resource opened here must be added with k.onClose so it is released on shutdown
+func (k *Kernel) initAdapter(cfg *config.Config) (*infrastructure.Adapter, error) {
+	mysql, err := db.NewMySQL(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	k.onClose("mysql", mysql.Close)
+
+	redis, err := db.NewRedis(cfg.Redis)
+	if err != nil {
+		return nil, err
+	}
+	k.onClose("redis", redis.Close)
+
+	if cfg.Metrics.Enabled {
+		if err := registerPoolMetrics(mysql, redis); err != nil {
+			return nil, fmt.Errorf("metrics: %w", err)
+		}
+	}
+
+	asynqClient, err := pkgqueue.NewAsynqClient(cfg.Queue, redis)
+	if err != nil {
+		return nil, err
+	}
+	k.onClose("asynq client", asynqClient.Close)
+
+	// every service mode needs the database, redis and the broker to be ready
+	timeout := cfg.Health.CheckTimeout
+	health.Default().Register(
+		health.Check{Name: "mysql", Func: health.SQL(mysql), Timeout: timeout, Critical: true},
+		health.Check{Name: "redis", Func: health.Redis(redis), Timeout: timeout, Critical: true},
+		health.Check{Name: "asynq", Func: health.Asynq(asynq.NewInspectorFromRedisClient(redis)), Timeout: timeout, Critical: true},
+	)
+	queue := pkgqueue.NewAsynqQueue(asynqClient)
+
+	authenticator, err := initAuth(cfg.Auth, mysql, redis)
+	if err != nil {
+		return nil, err
+	}
+
+	return &infrastructure.Adapter{
+		DB: &infrastructure.DatabaseConnection{
+			Sql:   mysql,
+			Redis: redis,
+		},
+		Queue:   &queue,
+		Auth:    authenticator,
+		Watcher: k.Watcher,
+	}, nil
+}
+
+func (k *Kernel) onClose(name string, fn func() error) {
+	k.closers = append(k.closers, closer{name: name, close: fn})
+}
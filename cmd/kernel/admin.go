@@ -0,0 +1,179 @@
+package kernel
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/health"
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/shutdown"
+)
+
+// AdminComponent is the name of the admin listener in failure reports
+const AdminComponent = "admin"
+
+// started is when the process started, reported as uptime
+var started = time.Now()
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Environment string    `json:"environment"`
+	GoVersion   string    `json:"go_version"`
+	Module      string    `json:"module,omitempty"`
+	Revision    string    `json:"vcs_revision,omitempty"`
+	CommitTime  string    `json:"vcs_time,omitempty"`
+	Modified    bool      `json:"vcs_modified,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	Uptime      string    `json:"uptime"`
+}
+
+// RouteInfo is an HTTP route of a module
+type RouteInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Name   string `json:"name,omitempty"`
+}
+
+// ModuleInfo is a registered module with its HTTP routes
+type ModuleInfo struct {
+	Name         string      `json:"name"`
+	Prefix       string      `json:"prefix"`
+	Dependencies []string    `json:"dependencies"`
+	Routes       []RouteInfo `json:"routes"`
+}
+
+// ServeAdmin serves the admin router on ADMIN_ADDR when ADMIN_ENABLED is set
+// and registers its shutdown on the coordinator
+func (k *Kernel) ServeAdmin(sd *shutdown.Coordinator, timeout time.Duration) error {
+	cfg := k.Config().Admin
+	if !cfg.Enabled {
+		return nil
+	}
+
+	server := &http.Server{Handler: k.AdminHandler(), ReadHeaderTimeout: 5 * time.Second}
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("admin server: %w", err)
+	}
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			sd.Fail(AdminComponent, err)
+		}
+	}()
+
+	sd.Register("admin server", timeout, func(ctx context.Context) error {
+		return server.Shutdown(ctx)
+	})
+	return nil
+}
+
+// AdminHandler returns the admin router, every endpoint requires the ADMIN_TOKEN bearer token
+// of the live config, so a rotated token applies on the next config reload
+//
+//	/debug/pprof/  the net/http/pprof profiles
+//	/info          name, version and VCS revision of the binary
+//	/config        the live configuration, secrets redacted
+//	/health        the readiness report with the error of every failing check
+//	/modules       the registered modules with their HTTP routes
+//	/log/level     GET the log level, PUT {"level":"debug"} to change it
+func (k *Kernel) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("GET /info", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, k.BuildInfo())
+	})
+	mux.HandleFunc("GET /config", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, k.Watcher.Current().Describe(true))
+	})
+	mux.HandleFunc("GET /modules", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, k.ModuleInfo())
+	})
+	mux.Handle("GET /health", health.Default().ReadinessDetailHandler())
+	mux.Handle("/log/level", logging.Level())
+
+	return requireToken(func() string { return k.Watcher.Current().Admin.Token.Value() }, mux)
+}
+
+// BuildInfo describes the running binary from the app config and the VCS stamp of the build
+func (k *Kernel) BuildInfo() BuildInfo {
+	app := k.Watcher.Current().App
+	info := BuildInfo{
+		Name:        app.Name,
+		Version:     app.Version,
+		Environment: app.Environment,
+		GoVersion:   runtime.Version(),
+		StartedAt:   started,
+		Uptime:      time.Since(started).Round(time.Second).String(),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = build.Main.Path
+	for _, s := range build.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.CommitTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// ModuleInfo lists the modules in boot order, with the HTTP routes each one registered
+func (k *Kernel) ModuleInfo() []ModuleInfo {
+	var modules []ModuleInfo
+	for _, mdl := range k.Registry.Modules() {
+		info := mdl.GetInfo()
+		m := ModuleInfo{Name: info.Name, Prefix: info.Prefix, Dependencies: info.Dependencies, Routes: k.routes[info.Name]}
+		if m.Dependencies == nil {
+			m.Dependencies = []string{}
+		}
+		if m.Routes == nil {
+			m.Routes = []RouteInfo{}
+		}
+		modules = append(modules, m)
+	}
+	return modules
+}
+
+// requireToken rejects requests without the current bearer token, every request is rejected when the token is empty
+func requireToken(current func() string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := current()
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,141 @@
+package kernel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/module"
+	"github.com/fatkulnurk/gostarter/shared/constant"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type adminModule struct {
+	name, prefix string
+	app          *fiber.App
+	paths        []string
+}
+
+func (m adminModule) GetInfo() *module.Module {
+	return &module.Module{Name: m.name, Prefix: m.prefix}
+}
+func (m adminModule) RegisterHTTP() {
+	for _, path := range m.paths {
+		m.app.Post(path, func(c *fiber.Ctx) error { return nil })
+	}
+}
+func (adminModule) RegisterTask()     {}
+func (adminModule) RegisterSchedule() {}
+
+func newAdminKernel(t *testing.T) *Kernel {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("ADMIN_ENABLED", "true")
+	t.Setenv("ADMIN_TOKEN", "s3cret")
+
+	cfg, err := config.New(constant.EnvironmentDevelopment)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	app := fiber.New()
+	app.Post("/ping", func(c *fiber.Ctx) error { return nil })
+
+	k := &Kernel{
+		Watcher:  config.NewWatcher(cfg),
+		Registry: module.NewRegistry(),
+		Delivery: &infrastructure.Delivery{HTTP: app},
+	}
+	// a prefix that is a segment of every path, and a route without the module prefix
+	for _, mdl := range []adminModule{
+		{name: "Api", prefix: "api", app: app},
+		{name: "Billing", prefix: "billing", app: app, paths: []string{"/api/v1/billing/invoices", "/invoices/export"}},
+	} {
+		k.Registry.Add(mdl)
+		k.registerHTTP(mdl)
+	}
+	return k
+}
+
+func adminRequest(handler http.Handler, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	handler := newAdminKernel(t).AdminHandler()
+
+	tests := []struct {
+		token string
+		want  int
+	}{
+		{"", http.StatusUnauthorized},
+		{"wrong", http.StatusUnauthorized},
+		{"s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		for _, path := range []string{"/info", "/config", "/modules", "/log/level", "/debug/pprof/"} {
+			if rec := adminRequest(handler, path, tt.token); rec.Code != tt.want {
+				t.Errorf("%s with token %q: expected %d, got %d", path, tt.token, tt.want, rec.Code)
+			}
+		}
+	}
+}
+
+func TestAdminTokenRotation(t *testing.T) {
+	k := newAdminKernel(t)
+	handler := k.AdminHandler()
+
+	t.Setenv("ADMIN_TOKEN", "rotated")
+	if err := k.Watcher.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if rec := adminRequest(handler, "/info", "s3cret"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the old token to be rejected, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, "/info", "rotated"); rec.Code != http.StatusOK {
+		t.Errorf("Expected the rotated token to be accepted, got %d", rec.Code)
+	}
+}
+
+func TestAdminEndpoints(t *testing.T) {
+	handler := newAdminKernel(t).AdminHandler()
+
+	var entries []config.Entry
+	if err := json.NewDecoder(adminRequest(handler, "/config", "s3cret").Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Key == "ADMIN_TOKEN" && e.Value != config.RedactedValue {
+			t.Errorf("Expected the admin token to be redacted, got %q", e.Value)
+		}
+	}
+
+	var modules []ModuleInfo
+	if err := json.NewDecoder(adminRequest(handler, "/modules", "s3cret").Body).Decode(&modules); err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 || len(modules[0].Routes) != 0 {
+		t.Fatalf("Expected the api module without routes, got %+v", modules)
+	}
+	if routes := modules[1].Routes; len(routes) != 2 || routes[0].Path != "/api/v1/billing/invoices" || routes[1].Path != "/invoices/export" {
+		t.Errorf("Expected the billing module with its two routes, got %+v", routes)
+	}
+
+	var info BuildInfo
+	if err := json.NewDecoder(adminRequest(handler, "/info", "s3cret").Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "GoStarter" || info.GoVersion == "" {
+		t.Errorf("Expected the app name and the Go version, got %+v", info)
+	}
+}
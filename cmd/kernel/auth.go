@@ -0,0 +1,100 @@
+package kernel
+
+import (
+	"database/sql"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/fatkulnurk/gostarter/pkg/auth"
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// initAuth builds the authenticators enabled in the config, tried in the order JWT, API key, basic auth
+// With none enabled the chain rejects every request behind middleware.Authenticate
+func initAuth(cfg *config.Auth, mysql *sql.DB, redis *redis.Client) (auth.Chain, error) {
+	var chain auth.Chain
+
+	if len(cfg.JWTAlgorithms) > 0 {
+		keys, err := jwtKeys(cfg)
+		if err != nil {
+			return nil, err
+		}
+		jwt, err := auth.NewJWT(auth.JWTOptions{
+			Algorithms:  cfg.JWTAlgorithms,
+			Keys:        keys,
+			Issuer:      cfg.JWTIssuer,
+			Audience:    cfg.JWTAudience,
+			Leeway:      cfg.JWTLeeway,
+			GroupsClaim: cfg.JWTGroupsClaim,
+		})
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwt)
+	}
+
+	switch cfg.APIKeyStore {
+	case "mysql":
+		chain = append(chain, auth.NewAPIKey(auth.NewMySQLAPIKeyStore(mysql), cfg.APIKeyHeader))
+	case "redis":
+		chain = append(chain, auth.NewAPIKey(auth.NewRedisAPIKeyStore(redis), cfg.APIKeyHeader))
+	}
+
+	if len(cfg.BasicUsers) > 0 {
+		users := make(map[string]auth.BasicUser, len(cfg.BasicUsers))
+		var problems validation.Errors
+		for name, hash := range cfg.BasicUsers {
+			// an unquoted hash in .env loses its $ parts to variable expansion and would fail every login
+			if _, err := bcrypt.Cost([]byte(hash.Value())); err != nil {
+				problems = append(problems, validation.Error{
+					Field:   "AUTH_BASIC_USERS",
+					Message: fmt.Sprintf("the password of %q is not a bcrypt hash, single-quote the value in .env files", name),
+				})
+			}
+			users[name] = auth.BasicUser{PasswordHash: hash.Value(), Groups: basicGroups(cfg.BasicGroups[name])}
+		}
+		if problems.HasErrors() {
+			return nil, &config.Error{Problems: problems}
+		}
+		chain = append(chain, auth.NewBasic(cfg.BasicRealm, users))
+	}
+	return chain, nil
+}
+
+// basicGroups splits the | separated groups of a basic user
+func basicGroups(raw string) []string {
+	var groups []string
+	for _, group := range strings.Split(raw, "|") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// jwtKeys collects the verification keys, the secret and the public key file verify tokens without kid
+func jwtKeys(cfg *config.Auth) (auth.Keys, error) {
+	keys := auth.Keys{}
+	if secret := cfg.JWTSecret.Value(); secret != "" {
+		keys[""] = []byte(secret)
+	}
+	if cfg.JWTPublicKeyFile != "" {
+		key, err := auth.LoadPublicKey(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_JWT_PUBLIC_KEY_FILE: %w", err)
+		}
+		keys[""] = key
+	}
+	if cfg.JWTJWKSFile != "" {
+		set, err := auth.LoadJWKS(cfg.JWTJWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_JWT_JWKS_FILE: %w", err)
+		}
+		maps.Copy(keys, set)
+	}
+	return keys, nil
+}
@@ -0,0 +1,43 @@
+package kernel
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/auth"
+	"github.com/fatkulnurk/gostarter/pkg/config"
+)
+
+func TestInitAuthRejectsBrokenHash(t *testing.T) {
+	hash, err := auth.HashPassword("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// what godotenv makes of an unquoted $2a$04$... hash
+	_, err = initAuth(&config.Auth{BasicUsers: map[string]config.Secret{"ops": config.Secret(hash), "ci": "a$l7dFfWQ"}}, nil, nil)
+	var cfgErr *config.Error
+	if !errors.As(err, &cfgErr) || len(cfgErr.Problems.ForField("AUTH_BASIC_USERS")) != 1 {
+		t.Fatalf("Expected one AUTH_BASIC_USERS problem, got %v", err)
+	}
+
+	chain, err := initAuth(&config.Auth{
+		BasicUsers:  map[string]config.Secret{"ops": config.Secret(hash)},
+		BasicGroups: map[string]string{"ops": "admin | deploy"},
+	}, nil, nil)
+	if err != nil || len(chain) != 1 {
+		t.Fatalf("Expected a basic authenticator, got %v, %v", chain, err)
+	}
+
+	credentials := "Basic " + base64.StdEncoding.EncodeToString([]byte("ops:s3cret"))
+	principal, err := chain[0].Authenticate(context.Background(), func(key string) string { return credentials })
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	if !slices.Equal(principal.Groups, []string{"admin", "deploy"}) {
+		t.Errorf("Expected the groups from AUTH_BASIC_GROUPS, got %v", principal.Groups)
+	}
+}
@@ -0,0 +1,245 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/health"
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/module"
+	"github.com/fatkulnurk/gostarter/pkg/shutdown"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+)
+
+// Kernel is the application core shared by every service mode
+// It builds the adapter once and holds the module registry, each mode only declares the deliveries it needs
+type Kernel struct {
+	Watcher  *config.Watcher // holds the live config, reloaded while the kernel runs
+	Adapter  *infrastructure.Adapter
+	Delivery *infrastructure.Delivery
+	Registry *module.Registry
+	Output   io.Writer // where module registration is printed, os.Stdout by default
+
+	closers   []closer
+	stopWatch context.CancelFunc
+	routes    map[string][]RouteInfo // HTTP routes registered by each module, keyed by module name
+}
+
+// New creates a kernel and builds the infrastructure adapter from the config
+// If an adapter fails to open, the adapters opened before it are closed again
+func New(cfg *config.Config) (*Kernel, error) {
+	k := &Kernel{
+		Watcher:  config.NewWatcher(cfg),
+		Registry: module.NewRegistry(),
+		Output:   os.Stdout,
+	}
+
+	if err := k.initTracing(cfg); err != nil {
+		return nil, err
+	}
+
+	adapter, err := k.initAdapter(cfg)
+	if err != nil {
+		if closeErr := k.Close(context.Background()); closeErr != nil {
+			return nil, errors.Join(err, closeErr)
+		}
+		return nil, err
+	}
+	k.Adapter = adapter
+
+	return k, nil
+}
+
+// Config returns the current config snapshot, it changes when the watcher reloads
+func (k *Kernel) Config() *config.Config {
+	return k.Watcher.Current()
+}
+
+// Boot creates every module against the given delivery, orders them by their dependencies
+// and registers the module's HTTP routes, tasks and schedules for each delivery that is set, and its health checks
+func (k *Kernel) Boot(delivery *infrastructure.Delivery) error {
+	k.Delivery = delivery
+	for _, factory := range modules {
+		k.Registry.Add(factory(k.Adapter, delivery))
+	}
+	if err := k.Registry.Sort(); err != nil {
+		return err
+	}
+	if err := k.Registry.Resolve(); err != nil {
+		return err
+	}
+	health.Default().Register(k.Registry.HealthChecks()...)
+
+	_, _ = fmt.Fprintf(k.Output, "-------Register module------\n")
+	for idx, mdl := range k.Registry.Modules() {
+		_, _ = fmt.Fprintf(k.Output, "number: %d\n", idx+1)
+		_, _ = fmt.Fprintf(k.Output, "Registering module: %s\n", mdl.GetInfo().Name)
+		_, _ = fmt.Fprintf(k.Output, "Prefix: %s\n", mdl.GetInfo().Prefix)
+		if delivery.HTTP != nil {
+			k.registerHTTP(mdl)
+		}
+		if delivery.Task != nil {
+			mdl.RegisterTask()
+		}
+		if delivery.Schedule != nil {
+			mdl.RegisterSchedule()
+		}
+		_, _ = fmt.Fprintf(k.Output, "-------------------------\n")
+	}
+	return nil
+}
+
+// registerHTTP registers the routes of a module and records them as the routes of that module
+func (k *Kernel) registerHTTP(mdl module.IModule) {
+	seen := make(map[string]bool)
+	for _, route := range k.Delivery.HTTP.GetRoutes(true) {
+		seen[route.Method+" "+route.Path] = true
+	}
+
+	mdl.RegisterHTTP()
+
+	var routes []RouteInfo
+	for _, route := range k.Delivery.HTTP.GetRoutes(true) {
+		if !seen[route.Method+" "+route.Path] {
+			routes = append(routes, RouteInfo{Method: route.Method, Path: route.Path, Name: route.Name})
+		}
+	}
+	if k.routes == nil {
+		k.routes = make(map[string][]RouteInfo)
+	}
+	k.routes[mdl.GetInfo().Name] = routes
+}
+
+// Start runs the OnStart hook of every module in registration order and starts watching the config
+// If one module fails, the modules already started are stopped again
+func (k *Kernel) Start(ctx context.Context) error {
+	if err := k.Registry.Start(ctx); err != nil {
+		return err
+	}
+
+	watchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	k.stopWatch = cancel
+	// a section with a subscriber is applied at runtime, its subscriber warns about the settings it can't apply
+	k.Watcher.Subscribe("", func(old, new *config.Config) {
+		for section := range config.ChangedSections(old, new) {
+			if !k.Watcher.Subscribed(section) {
+				logging.Warning(watchCtx, "Config section changed, it applies on restart", logging.NewField("section", section))
+			}
+		}
+	})
+	k.Watcher.Subscribe("Logging", func(old, new *config.Config) {
+		reloadLogging(watchCtx, old.Logging, new.Logging)
+	})
+	k.Watcher.Subscribe("Admin", func(old, new *config.Config) {
+		reloadAdmin(watchCtx, old.Admin, new.Admin)
+	})
+	go k.Watcher.Watch(watchCtx, k.Config().App.ConfigWatchInterval)
+	return nil
+}
+
+// Stop stops watching the config and runs the OnStop hook of every started module in reverse registration order
+func (k *Kernel) Stop(ctx context.Context) error {
+	if k.stopWatch != nil {
+		k.stopWatch()
+		k.stopWatch = nil
+	}
+	return k.Registry.Stop(ctx)
+}
+
+// Close releases every adapter in reverse order of creation
+// An adapter that does not close before ctx is done is logged and left behind
+func (k *Kernel) Close(ctx context.Context) error {
+	var errs []error
+	for i := len(k.closers) - 1; i >= 0; i-- {
+		c := k.closers[i]
+		done := make(chan error, 1)
+		go func() {
+			done <- c.close()
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("close %s: %w", c.name, err))
+			}
+		case <-ctx.Done():
+			logging.Warning(ctx, "Adapter did not close before the deadline", logging.NewField("adapter", c.name))
+			errs = append(errs, fmt.Errorf("close %s: %w", c.name, ctx.Err()))
+		}
+	}
+	k.closers = nil
+	return errors.Join(errs...)
+}
+
+// RegisterShutdown registers the kernel's shutdown hooks on the coordinator:
+// modules are stopped first, then every adapter is closed
+// Register the delivery hooks after this call so they run before these ones
+func (k *Kernel) RegisterShutdown(sd *shutdown.Coordinator, timeout time.Duration) {
+	sd.Register("adapters", timeout, k.Close)
+	sd.Register("modules", timeout, k.Stop)
+}
+
+// Commands returns the CLI commands contributed by modules, grouped under each module prefix
+func (k *Kernel) Commands() []*cli.Command {
+	return moduleCommands(k.Registry)
+}
+
+// ModuleCommands returns the CLI commands contributed by modules without opening any adapter,
+// so they can be listed in help and parsed before anything connects
+// The modules are created on an empty adapter: run the commands of a booted kernel, see Commands,
+// and keep module factories from using the adapter before the module runs
+func ModuleCommands() []*cli.Command {
+	registry := module.NewRegistry()
+	adapter := &infrastructure.Adapter{DB: &infrastructure.DatabaseConnection{}}
+	for _, factory := range modules {
+		registry.Add(factory(adapter, &infrastructure.Delivery{}))
+	}
+	return moduleCommands(registry)
+}
+
+func moduleCommands(registry *module.Registry) []*cli.Command {
+	var commands []*cli.Command
+	for _, mdl := range registry.Modules() {
+		provider, ok := mdl.(module.CommandProvider)
+		if !ok {
+			continue
+		}
+		commands = append(commands, &cli.Command{
+			Name:        mdl.GetInfo().Prefix,
+			Short:       fmt.Sprintf("Commands of the %s module", mdl.GetInfo().Name),
+			Subcommands: provider.Commands(),
+		})
+	}
+	return commands
+}
+
+// reloadLogging applies a new log level, the other logging settings take effect on restart
+func reloadLogging(ctx context.Context, old, new *config.Logging) {
+	if old.Level != new.Level {
+		if level, err := logging.ParseLevel(new.Level); err == nil {
+			logging.Level().Set(level)
+		}
+	}
+
+	restart := *old
+	restart.Level = new.Level
+	if !reflect.DeepEqual(&restart, new) {
+		logging.Warning(ctx, "Logging settings other than the level change on restart")
+	}
+}
+
+// reloadAdmin warns about admin settings other than the token, which is read on every request
+func reloadAdmin(ctx context.Context, old, new *config.Admin) {
+	restart := *old
+	restart.Token = new.Token
+	if !reflect.DeepEqual(&restart, new) {
+		logging.Warning(ctx, "Admin settings other than the token change on restart")
+	}
+}
@@ -0,0 +1,12 @@
+package kernel
+
+import (
+	"github.com/fatkulnurk/gostarter/internal/example"
+	"github.com/fatkulnurk/gostarter/pkg/module"
+)
+
+// modules lists every module of the application, shared by all service modes
+// add new modules here so http, worker and scheduler pick them up
+var modules = []module.Factory{
+	example.New,
+}
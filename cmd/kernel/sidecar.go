@@ -0,0 +1,70 @@
+package kernel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/health"
+	"github.com/fatkulnurk/gostarter/pkg/metrics"
+	"github.com/fatkulnurk/gostarter/pkg/shutdown"
+	"github.com/redis/go-redis/v9"
+)
+
+// SidecarComponent is the name of the metrics and probe listeners in failure reports
+const SidecarComponent = "sidecar"
+
+// ServeSidecar serves the metrics on METRICS_ADDR and the health probes on HEALTH_ADDR
+// for the service modes without an HTTP delivery, on one listener when both addresses are the same.
+// It registers the shutdown of every listener on the coordinator, an empty address disables its endpoints
+func (k *Kernel) ServeSidecar(sd *shutdown.Coordinator, timeout time.Duration) error {
+	muxes := make(map[string]*http.ServeMux)
+	handle := func(addr, path string, handler http.Handler) {
+		if addr == "" {
+			return
+		}
+		if muxes[addr] == nil {
+			muxes[addr] = http.NewServeMux()
+		}
+		muxes[addr].Handle(path, handler)
+	}
+
+	cfg := k.Config()
+	if cfg.Metrics.Enabled {
+		handle(cfg.Metrics.Addr, cfg.Metrics.Path, metrics.Default().Handler())
+	}
+	handle(cfg.Health.Addr, cfg.Health.LivenessPath, health.Default().LivenessHandler())
+	// the sidecar listens next to the metrics, so it may report why a check fails
+	handle(cfg.Health.Addr, cfg.Health.ReadinessPath, health.Default().ReadinessDetailHandler())
+
+	for addr, mux := range muxes {
+		server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("sidecar: %w", err)
+		}
+		go func() {
+			if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				sd.Fail(SidecarComponent, err)
+			}
+		}()
+
+		sd.Register("sidecar "+addr, timeout, func(ctx context.Context) error {
+			return server.Shutdown(ctx)
+		})
+	}
+	return nil
+}
+
+// registerPoolMetrics exports the connection pool statistics of the database adapters
+func registerPoolMetrics(mysql *sql.DB, redis *redis.Client) error {
+	m := metrics.Default()
+	return errors.Join(
+		m.RegisterDB("mysql", mysql),
+		m.RegisterRedis("redis", redis),
+	)
+}
@@ -0,0 +1,39 @@
+package kernel
+
+import (
+	"context"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/tracing"
+)
+
+// tracingFlushTimeout bounds how long the spans still buffered may take to export on shutdown
+const tracingFlushTimeout = 5 * time.Second
+
+// initTracing installs the tracer provider when tracing is enabled
+// It is set up before the adapters so it is flushed after every one of them is closed
+func (k *Kernel) initTracing(cfg *config.Config) error {
+	if !cfg.Tracing.Enabled {
+		return nil
+	}
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName:    cfg.App.Name,
+		ServiceVersion: cfg.App.Version,
+		Environment:    cfg.App.Environment,
+		Exporter:       cfg.Tracing.Exporter,
+		Endpoint:       cfg.Tracing.OTLPEndpoint,
+		Insecure:       cfg.Tracing.OTLPInsecure,
+		SampleRatio:    cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return err
+	}
+	k.onClose("tracing", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+		return shutdown(ctx)
+	})
+	return nil
+}
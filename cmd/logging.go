@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/metrics"
+)
+
+// initLogging installs the logger configured by the LOG_* variables and returns the closer of its outputs
+// The entries dropped by sampling are exported as metrics unless metrics are disabled
+func initLogging(cfg *config.Logging, metricsEnabled bool) (io.Closer, error) {
+	level, err := logging.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	logging.Level().Set(level)
+
+	opts := LoggingOptions(cfg)
+	logger, closer, err := logging.New(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Sampler != nil && metricsEnabled {
+		if err := metrics.Default().RegisterLogDrops("logger", opts.Sampler); err != nil {
+			_ = closer.Close()
+			return nil, err
+		}
+	}
+	logging.InitLogging(logger)
+	return closer, nil
+}
+
+// LoggingOptions maps the logging config to the options of logging.New
+func LoggingOptions(cfg *config.Logging) logging.Options {
+	opts := logging.Options{
+		Backend:   cfg.Backend,
+		Format:    cfg.Format,
+		Outputs:   cfg.Outputs,
+		AddSource: cfg.AddSource,
+		File: logging.RotateOptions{
+			Path:       cfg.FilePath,
+			Rotation:   cfg.FileRotation,
+			MaxSize:    int64(cfg.FileMaxSizeMB) << 20,
+			MaxAge:     cfg.FileMaxAge,
+			MaxBackups: cfg.FileMaxBackups,
+		},
+	}
+	if cfg.Redact {
+		opts.Redact = &logging.RedactOptions{
+			Keys:        cfg.RedactKeys,
+			Emails:      cfg.RedactEmails,
+			CardNumbers: cfg.RedactCards,
+		}
+	}
+	if cfg.Sampling {
+		opts.Sampler = logging.NewSampler(logging.SampleOptions{
+			Interval:   cfg.SampleInterval,
+			First:      cfg.SampleFirst,
+			Thereafter: cfg.SampleEvery,
+		})
+	}
+	return opts
+}
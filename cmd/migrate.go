@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/pkg/db"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+)
+
+func migrateCommand(a *app) *cli.Command {
+	migrate := &cli.Command{
+		Name:  "migrate",
+		Short: "Apply pending SQL migrations",
+	}
+	// accepted before and after the subcommand, like migrate -dir db up or migrate up -dir db
+	dir := migrate.Flags().String("dir", "migrations", "directory containing the *.sql migration files")
+
+	apply := func(ctx context.Context, args []string) error {
+		k, err := a.boot(&infrastructure.Delivery{})
+		if err != nil {
+			return err
+		}
+
+		applied, err := db.Migrate(ctx, k.Adapter.DB.Sql, os.DirFS(*dir))
+		for _, name := range applied {
+			fmt.Printf("applied %s\n", name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("nothing to migrate")
+		}
+		return nil
+	}
+	migrate.Run = apply
+
+	up := &cli.Command{
+		Name:  "up",
+		Short: "Apply pending SQL migrations",
+		Run:   apply,
+	}
+	status := &cli.Command{
+		Name:  "status",
+		Short: "List migrations and whether they are applied",
+		Run: func(ctx context.Context, args []string) error {
+			k, err := a.boot(&infrastructure.Delivery{})
+			if err != nil {
+				return err
+			}
+
+			migrations, err := db.Migrations(ctx, k.Adapter.DB.Sql, os.DirFS(*dir))
+			if err != nil {
+				return err
+			}
+			for _, m := range migrations {
+				state := "pending"
+				if m.Applied {
+					state = "applied"
+				}
+				fmt.Printf("%-8s %s\n", state, m.Name)
+			}
+			return nil
+		},
+	}
+	for _, sub := range []*cli.Command{up, status} {
+		sub.Flags().StringVar(dir, "dir", *dir, "directory containing the *.sql migration files")
+	}
+	migrate.Add(up, status)
+	return migrate
+}
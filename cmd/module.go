@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/pkg/module"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+)
+
+func moduleCommand(a *app) *cli.Command {
+	list := &cli.Command{
+		Name:  "list",
+		Short: "List registered modules in boot order",
+		Run: func(ctx context.Context, args []string) error {
+			k, err := a.boot(&infrastructure.Delivery{})
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "NAME\tPREFIX\tDEPENDENCIES\tCOMMANDS")
+			for _, mdl := range k.Registry.Modules() {
+				info := mdl.GetInfo()
+				deps := "-"
+				if len(info.Dependencies) > 0 {
+					deps = strings.Join(info.Dependencies, ",")
+				}
+				commands := "-"
+				if _, ok := mdl.(module.CommandProvider); ok {
+					commands = info.Prefix
+				}
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", info.Name, info.Prefix, deps, commands)
+			}
+			return tw.Flush()
+		},
+	}
+
+	return &cli.Command{
+		Name:        "module",
+		Short:       "Inspect registered modules",
+		Subcommands: []*cli.Command{list},
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatkulnurk/gostarter/cmd/http"
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+)
+
+func routesCommand(a *app) *cli.Command {
+	return &cli.Command{
+		Name:  "routes",
+		Short: "List the HTTP routes registered by modules",
+		Run: func(ctx context.Context, args []string) error {
+			cfg, err := a.config()
+			if err != nil {
+				return err
+			}
+
+			delivery := &infrastructure.Delivery{HTTP: http.NewApp(cfg, nil)}
+			if _, err := a.boot(delivery); err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "METHOD\tPATH\tNAME")
+			for _, route := range delivery.HTTP.GetRoutes(true) {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", route.Method, route.Path, route.Name)
+			}
+			return tw.Flush()
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+	"github.com/hibiken/asynq"
+)
+
+func scheduleCommand(a *app) *cli.Command {
+	list := &cli.Command{
+		Name:  "list",
+		Short: "List the schedules registered by running schedulers",
+		Run: func(ctx context.Context, args []string) error {
+			k, err := a.boot(&infrastructure.Delivery{})
+			if err != nil {
+				return err
+			}
+
+			inspector := asynq.NewInspectorFromRedisClient(k.Adapter.DB.Redis)
+			entries, err := inspector.SchedulerEntries()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println("no schedules found, is a scheduler running?")
+				return nil
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "ID\tSPEC\tTASK\tNEXT\tPREV")
+			for _, e := range entries {
+				prev := "-"
+				if !e.Prev.IsZero() {
+					prev = e.Prev.Format(time.RFC3339)
+				}
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.ID, e.Spec, e.Task.Type(), e.Next.Format(time.RFC3339), prev)
+			}
+			return tw.Flush()
+		},
+	}
+
+	return &cli.Command{
+		Name:        "schedule",
+		Short:       "Inspect scheduled jobs",
+		Subcommands: []*cli.Command{list},
+	}
+}
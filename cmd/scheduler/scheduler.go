@@ -1,80 +1,81 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
-	"github.com/fatkulnurk/gostarter/internal/example"
+	"github.com/fatkulnurk/gostarter/cmd/kernel"
 	"github.com/fatkulnurk/gostarter/pkg/config"
-	"github.com/fatkulnurk/gostarter/pkg/db"
-	"github.com/fatkulnurk/gostarter/pkg/module"
-	pkgqueue "github.com/fatkulnurk/gostarter/pkg/queue"
+	"github.com/fatkulnurk/gostarter/pkg/shutdown"
 	"github.com/fatkulnurk/gostarter/shared/infrastructure"
 	"github.com/hibiken/asynq"
 )
 
-func Serve(cfg *config.Config) {
-	// adapter, only register what you need
-	adapter := func(cfg *config.Config) *infrastructure.Adapter {
-		mysql, err := db.NewMySQL(cfg.Database)
-		if err != nil {
-			panic(err)
-		}
+// Component is the name of the scheduler delivery in service mode lists and failure policies
+const Component = "scheduler"
 
-		redis, err := db.NewRedis(cfg.Redis)
-		if err != nil {
-			panic(err)
-		}
-
-		asynqClient, err := pkgqueue.NewAsynqClient(cfg.Queue, redis)
-		if err != nil {
-			panic(err)
-		}
-		queue := pkgqueue.NewAsynqQueue(asynqClient)
+// Serve runs the scheduler until a shutdown signal or a failure, and returns the failure
+func Serve(cfg *config.Config) error {
+	k, err := kernel.New(cfg)
+	if err != nil {
+		return err
+	}
+	sd := shutdown.New()
+	k.RegisterShutdown(sd, cfg.Schedule.ShutdownTimeout)
 
-		return &infrastructure.Adapter{
-			DB: &infrastructure.DatabaseConnection{
-				Sql:   mysql,
-				Redis: redis,
-			},
-			Queue: &queue,
-		}
-	}(cfg)
+	scheduler, err := New(cfg, k)
+	if err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
 
 	// delivery, only register what you need
-	delivery := func(cfg *config.Config, adapter *infrastructure.Adapter) *infrastructure.Delivery {
-		timeLocation, err := time.LoadLocation(cfg.Schedule.Timezone)
-		if err != nil {
-			panic(err)
-		}
+	delivery := &infrastructure.Delivery{
+		Schedule: scheduler,
+	}
+	if err := k.Boot(delivery); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
+	if err := k.Start(context.Background()); err != nil {
+		return errors.Join(fmt.Errorf("could not start modules: %w", err), sd.Shutdown(context.Background()))
+	}
+
+	if err := Start(cfg, delivery, sd); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
+	if err := k.ServeSidecar(sd, cfg.Schedule.ShutdownTimeout); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
+	if err := k.ServeAdmin(sd, cfg.Schedule.ShutdownTimeout); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
 
-		mux := asynq.NewServeMux()
-		scheduler := asynq.NewSchedulerFromRedisClient(adapter.DB.Redis, &asynq.SchedulerOpts{
-			Location: timeLocation,
-		})
-		return &infrastructure.Delivery{
-			Task:     mux,
-			Schedule: scheduler,
-		}
-	}(cfg, adapter)
+	waitErr := sd.Wait(context.Background())
+	return errors.Join(waitErr, sd.Shutdown(context.Background()))
+}
 
-	// Register modules
-	func() {
-		var modules []module.IModule
-		modules = append(modules, example.New(adapter, delivery))
+// New creates the asynq scheduler in the configured timezone
+func New(cfg *config.Config, k *kernel.Kernel) (*asynq.Scheduler, error) {
+	timeLocation, err := time.LoadLocation(cfg.Schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
 
-		fmt.Printf("-------Register module------\n")
-		for idx, module := range modules {
-			fmt.Printf("number: %d\n", idx+1)
-			fmt.Printf("Registering module: %s\n", module.GetInfo().Name)
-			fmt.Printf("Prefix: %s\n", module.GetInfo().Prefix)
-			module.RegisterSchedule()
-			fmt.Printf("-------------------------\n")
-		}
-	}()
+	return asynq.NewSchedulerFromRedisClient(k.Adapter.DB.Redis, &asynq.SchedulerOpts{
+		Location: timeLocation,
+	}), nil
+}
 
-	if err := delivery.Schedule.Run(); err != nil {
-		log.Fatal(err)
+// Start runs the scheduler in the background and registers its shutdown on the coordinator
+func Start(cfg *config.Config, delivery *infrastructure.Delivery, sd *shutdown.Coordinator) error {
+	if err := delivery.Schedule.Start(); err != nil {
+		return fmt.Errorf("scheduler: %w", err)
 	}
+
+	sd.Register("scheduler", cfg.Schedule.ShutdownTimeout, func(ctx context.Context) error {
+		delivery.Schedule.Shutdown()
+		return nil
+	})
+	return nil
 }
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/cmd/http"
+	"github.com/fatkulnurk/gostarter/cmd/kernel"
+	"github.com/fatkulnurk/gostarter/cmd/scheduler"
+	"github.com/fatkulnurk/gostarter/cmd/worker"
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/shutdown"
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+)
+
+func serveCommand(a *app) *cli.Command {
+	serve := &cli.Command{
+		Name:  "serve",
+		Usage: "<service>[,<service>...]",
+		Short: "Run one or more services: " + strings.Join(services, ", ") + " or " + ServiceAll,
+		Run: func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return cli.Usagef("exactly one service list is required")
+			}
+			cfg, err := a.config()
+			if err != nil {
+				return err
+			}
+			return ServeApp(args[0], cfg)
+		},
+	}
+
+	for _, svc := range append(slices.Clone(services), ServiceAll) {
+		serve.Add(&cli.Command{
+			Name:  svc,
+			Short: "Run the " + svc + " service",
+			Run: func(ctx context.Context, args []string) error {
+				cfg, err := a.config()
+				if err != nil {
+					return err
+				}
+				return ServeApp(svc, cfg)
+			},
+		})
+	}
+	serve.Find(ServiceAll).Short = "Run every service in one process"
+	return serve
+}
+
+// ServiceAll selects every service mode
+const ServiceAll = "all"
+
+// services lists every service mode in start order
+var services = []string{http.Component, worker.Component, scheduler.Component}
+
+// ParseServices parses a --svc value into a list of service modes
+// It accepts a single mode, "all" or a comma-separated combination like "http,worker"
+func ParseServices(svc string) ([]string, error) {
+	if strings.TrimSpace(svc) == ServiceAll {
+		return services, nil
+	}
+
+	var selected []string
+	for _, s := range strings.Split(svc, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !slices.Contains(services, s) {
+			return nil, fmt.Errorf("invalid service %q, must be one of %s or %s", s, strings.Join(services, ", "), ServiceAll)
+		}
+		if !slices.Contains(selected, s) {
+			selected = append(selected, s)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no service selected")
+	}
+
+	// keep the start order stable whatever the order of the flag
+	slices.SortFunc(selected, func(a, b string) int {
+		return slices.Index(services, a) - slices.Index(services, b)
+	})
+	return selected, nil
+}
+
+// ServeCombined runs the selected deliveries in one process against a shared adapter
+// Every delivery is drained on shutdown, then modules are stopped and adapters are closed once
+func ServeCombined(selected []string, cfg *config.Config) error {
+	// every prefork child runs the same command line, so each one would start the other services again
+	if cfg.DeliveryHttp.Prefork && slices.Contains(selected, http.Component) {
+		return &config.Error{Problems: validation.Errors{{
+			Field:   "HTTP_PREFORK",
+			Message: "can't be used when http runs with other services in one process, serve http on its own",
+		}}}
+	}
+
+	policies := map[string]string{
+		http.Component:      cfg.DeliveryHttp.FailurePolicy,
+		worker.Component:    cfg.DeliveryQueue.FailurePolicy,
+		scheduler.Component: cfg.Schedule.FailurePolicy,
+	}
+	timeouts := map[string]time.Duration{
+		http.Component:      cfg.DeliveryHttp.ShutdownTimeout,
+		worker.Component:    cfg.DeliveryQueue.ShutdownTimeout,
+		scheduler.Component: cfg.Schedule.ShutdownTimeout,
+	}
+
+	sd := shutdown.New()
+	var timeout time.Duration
+	for _, svc := range selected {
+		policy, err := shutdown.ParsePolicy(policies[svc])
+		if err != nil {
+			return fmt.Errorf("%s: %w", svc, err)
+		}
+		sd.SetPolicy(svc, policy)
+		timeout = max(timeout, timeouts[svc])
+	}
+
+	k, err := kernel.New(cfg)
+	if err != nil {
+		return err
+	}
+	k.RegisterShutdown(sd, timeout)
+
+	// delivery, only register what the selected services need
+	delivery := &infrastructure.Delivery{}
+	for _, svc := range selected {
+		switch svc {
+		case http.Component:
+			delivery.HTTP = http.NewApp(cfg, k.Watcher)
+		case worker.Component:
+			delivery.Task = worker.NewMux(cfg)
+		case scheduler.Component:
+			if delivery.Schedule, err = scheduler.New(cfg, k); err != nil {
+				return errors.Join(err, sd.Shutdown(context.Background()))
+			}
+		}
+	}
+
+	if err := k.Boot(delivery); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
+	if err := k.Start(context.Background()); err != nil {
+		return errors.Join(fmt.Errorf("could not start modules: %w", err), sd.Shutdown(context.Background()))
+	}
+
+	// the coordinator aborts once every service failed, including an HTTP server that fails to listen later
+	sd.Starting(selected...)
+	for _, svc := range selected {
+		fmt.Printf("Starting %s...\n", svc)
+
+		var err error
+		switch svc {
+		case http.Component:
+			http.Start(cfg, delivery, sd)
+		case worker.Component:
+			err = worker.Start(cfg, k, delivery, sd)
+		case scheduler.Component:
+			err = scheduler.Start(cfg, delivery, sd)
+		}
+		if err != nil && sd.Fail(svc, err) {
+			break
+		}
+	}
+
+	// the HTTP server serves the metrics and the probes itself
+	if !slices.Contains(selected, http.Component) {
+		if err := k.ServeSidecar(sd, timeout); err != nil {
+			sd.Fail(kernel.SidecarComponent, err)
+		}
+	}
+	if err := k.ServeAdmin(sd, timeout); err != nil {
+		sd.Fail(kernel.AdminComponent, err)
+	}
+
+	waitErr := sd.Wait(context.Background())
+	fmt.Println("Shutting down gracefully...")
+	return errors.Join(waitErr, sd.Shutdown(context.Background()))
+}
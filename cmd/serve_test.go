@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/shared/constant"
+)
+
+func TestParseServices(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected []string
+		wantErr  bool
+	}{
+		{"all", []string{"http", "worker", "scheduler"}, false},
+		{"worker", []string{"worker"}, false},
+		{"scheduler, http,http", []string{"http", "scheduler"}, false},
+		{"http,,", []string{"http"}, false},
+		{"", nil, true},
+		{"http,mailer", nil, true},
+	}
+	for _, tt := range tests {
+		selected, err := ParseServices(tt.in)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(selected, tt.expected) {
+			t.Errorf("Expected %v (error %v) for %q, got %v (%v)", tt.expected, tt.wantErr, tt.in, selected, err)
+		}
+	}
+}
+
+func TestServeExitCode(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// nothing listens on this port, so the kernel fails to connect
+	t.Setenv("DB_PORT", "1")
+
+	for _, svc := range []string{"http", "worker", "scheduler", "http,worker"} {
+		if code := Execute(context.Background(), "gostarter", []string{"serve", svc}); code != cli.ExitError {
+			t.Errorf("Expected exit code %d when %s can't start, got %d", cli.ExitError, svc, code)
+		}
+	}
+}
+
+func TestServeCombinedRejectsPrefork(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HTTP_PREFORK", "true")
+	cfg, err := config.New(constant.EnvironmentDevelopment)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	err = ServeCombined([]string{"http", "worker"}, cfg)
+	var cfgErr *config.Error
+	if !errors.As(err, &cfgErr) || len(cfgErr.Problems.ForField("HTTP_PREFORK")) == 0 {
+		t.Errorf("Expected HTTP_PREFORK to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/pkg/queue"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+)
+
+func taskCommand(a *app) *cli.Command {
+	enqueue := &cli.Command{
+		Name:  "enqueue",
+		Usage: "<task> [json payload]",
+		Short: "Enqueue a task to the worker queue",
+	}
+	queueName := enqueue.Flags().String("queue", "", "queue name, the default queue when empty")
+	maxRetry := enqueue.Flags().Int("max-retry", 0, "maximum number of retries")
+	processIn := enqueue.Flags().Duration("process-in", 0, "delay before the task is processed")
+
+	enqueue.Run = func(ctx context.Context, args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return cli.Usagef("a task name and an optional json payload are required")
+		}
+
+		var payload json.RawMessage
+		if len(args) == 2 {
+			if !json.Valid([]byte(args[1])) {
+				return cli.Usagef("payload is not valid json")
+			}
+			payload = json.RawMessage(args[1])
+		}
+
+		k, err := a.boot(&infrastructure.Delivery{})
+		if err != nil {
+			return err
+		}
+
+		var opts []queue.Option
+		if *queueName != "" {
+			opts = append(opts, queue.QueueName(*queueName))
+		}
+		if *maxRetry > 0 {
+			opts = append(opts, queue.MaxRetry(*maxRetry))
+		}
+		if *processIn > 0 {
+			opts = append(opts, queue.ProcessIn(*processIn))
+		}
+
+		out, err := (*k.Adapter.Queue).Enqueue(ctx, args[0], payload, opts...)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("enqueued %s with id %s\n", args[0], out.TaskID)
+		return nil
+	}
+
+	return &cli.Command{
+		Name:        "task",
+		Short:       "Manage background tasks",
+		Subcommands: []*cli.Command{enqueue},
+	}
+}
@@ -1,71 +1,78 @@
 package worker
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"time"
 
-	"github.com/fatkulnurk/gostarter/internal/example"
+	"github.com/fatkulnurk/gostarter/cmd/kernel"
 	"github.com/fatkulnurk/gostarter/pkg/config"
-	"github.com/fatkulnurk/gostarter/pkg/db"
-	"github.com/fatkulnurk/gostarter/pkg/module"
-	pkgqueue "github.com/fatkulnurk/gostarter/pkg/queue"
+	"github.com/fatkulnurk/gostarter/pkg/metrics"
+	"github.com/fatkulnurk/gostarter/pkg/queue"
+	"github.com/fatkulnurk/gostarter/pkg/shutdown"
 	"github.com/fatkulnurk/gostarter/shared/infrastructure"
 	"github.com/hibiken/asynq"
 )
 
-func Serve(cfg *config.Config) {
-	// adapter, only register what you need
-	adapter := func(cfg *config.Config) *infrastructure.Adapter {
-		mysql, err := db.NewMySQL(cfg.Database)
-		if err != nil {
-			panic(err)
-		}
+// Component is the name of the worker delivery in service mode lists and failure policies
+const Component = "worker"
 
-		redis, err := db.NewRedis(cfg.Redis)
-		if err != nil {
-			panic(err)
-		}
+// Serve runs the worker until a shutdown signal or a failure, and returns the failure
+func Serve(cfg *config.Config) error {
+	k, err := kernel.New(cfg)
+	if err != nil {
+		return err
+	}
+	sd := shutdown.New()
+	k.RegisterShutdown(sd, cfg.DeliveryQueue.ShutdownTimeout)
 
-		asynqClient, err := pkgqueue.NewAsynqClient(cfg.Queue, redis)
-		if err != nil {
-			panic(err)
-		}
+	// delivery, only register what you need
+	delivery := &infrastructure.Delivery{
+		Task: NewMux(cfg),
+	}
+	if err := k.Boot(delivery); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
+	if err := k.Start(context.Background()); err != nil {
+		return errors.Join(fmt.Errorf("could not start modules: %w", err), sd.Shutdown(context.Background()))
+	}
 
-		queue := pkgqueue.NewAsynqQueue(asynqClient)
-		return &infrastructure.Adapter{
-			DB: &infrastructure.DatabaseConnection{
-				Redis: redis,
-				Sql:   mysql,
-			},
-			Queue: &queue,
-		}
-	}(cfg)
+	if err := Start(cfg, k, delivery, sd); err != nil {
+		return errors.Join(fmt.Errorf("could not run server: %w", err), sd.Shutdown(context.Background()))
+	}
+	if err := k.ServeSidecar(sd, cfg.DeliveryQueue.ShutdownTimeout); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
+	if err := k.ServeAdmin(sd, cfg.DeliveryQueue.ShutdownTimeout); err != nil {
+		return errors.Join(err, sd.Shutdown(context.Background()))
+	}
 
-	// delivery, only register what you need
-	delivery := func(cfg *config.Config) *infrastructure.Delivery {
-		mux := asynq.NewServeMux()
-		return &infrastructure.Delivery{
-			HTTP: nil,
-			Task: mux,
-		}
-	}(cfg)
+	waitErr := sd.Wait(context.Background())
+	return errors.Join(waitErr, sd.Shutdown(context.Background()))
+}
 
-	// Register modules
-	func() {
-		var modules []module.IModule
-		modules = append(modules, example.New(adapter, delivery))
+// shutdownGrace is how long the shutdown hook waits beyond QUEUE_SHUTDOWN_TIMEOUT
+// for asynq to requeue the unfinished tasks and stop its background processes
+const shutdownGrace = 2 * time.Second
 
-		fmt.Printf("-------Register mdl------\n")
-		for idx, mdl := range modules {
-			fmt.Printf("number: %d\n", idx+1)
-			fmt.Printf("Registering mdl: %s\n", mdl.GetInfo().Name)
-			fmt.Printf("Prefix: %s\n", mdl.GetInfo().Prefix)
-			mdl.RegisterTask()
-			fmt.Printf("-------------------------\n")
-		}
-	}()
+// NewMux creates the task mux with the global middlewares
+func NewMux(cfg *config.Config) *asynq.ServeMux {
+	mux := asynq.NewServeMux()
+	if cfg.Metrics.Enabled {
+		mux.Use(queue.MetricsMiddleware(metrics.Default()))
+	}
+	mux.Use(queue.MetadataMiddleware)
+	if cfg.Tracing.Enabled {
+		// after the metadata, which carries the trace of the enqueuing request
+		mux.Use(queue.TracingMiddleware)
+	}
+	return mux
+}
 
-	server := asynq.NewServerFromRedisClient(adapter.DB.Redis,
+// Start runs the asynq server in the background and registers its drain on the coordinator
+func Start(cfg *config.Config, k *kernel.Kernel, delivery *infrastructure.Delivery, sd *shutdown.Coordinator) error {
+	server := asynq.NewServerFromRedisClient(k.Adapter.DB.Redis,
 		asynq.Config{
 			// Specify how many concurrent workers to use
 			Concurrency: cfg.Queue.Concurrency,
@@ -75,11 +82,31 @@ func Serve(cfg *config.Config) {
 				"default":  3,
 				"low":      1,
 			},
+			// How long in-flight tasks may finish before they are pushed back to the queue
+			ShutdownTimeout: cfg.DeliveryQueue.ShutdownTimeout,
 			// See the godoc for other configuration options
 		},
 	)
 
-	if err := server.Run(delivery.Task); err != nil {
-		log.Fatalf("could not run server: %v", err)
+	if err := server.Start(delivery.Task); err != nil {
+		return fmt.Errorf("worker server: %w", err)
 	}
+
+	// stop pulling new tasks, then wait for in-flight tasks
+	// asynq pushes tasks still running after ShutdownTimeout back to the queue, the hook waits a little longer for that
+	sd.Register("worker server", cfg.DeliveryQueue.ShutdownTimeout+shutdownGrace, func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			server.Shutdown()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("worker server: %w", ctx.Err())
+		}
+	})
+	return nil
 }
@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"github.com/fatkulnurk/gostarter/internal/example/domain"
+	"github.com/fatkulnurk/gostarter/shared/binding"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -21,9 +22,23 @@ func (d *HttpDelivery) HandleHelloWorld(c *fiber.Ctx) error {
 	})
 }
 
+// ExampleApiRequest is bound from the query string, like ?name=Gopher
+type ExampleApiRequest struct {
+	Name string `query:"name" json:"name" bind:"query" validate:"strmaxlen=50"`
+}
+
 func (d *HttpDelivery) HandleExampleApi(c *fiber.Ctx) error {
+	input, err := binding.Bind[ExampleApiRequest](c)
+	if err != nil {
+		return err
+	}
+	name := input.Name
+	if name == "" {
+		name = "World"
+	}
+
 	return c.JSON(fiber.Map{
-		"message": "Hello, World!",
+		"message": "Hello, " + name + "!",
 		"status":  "success",
 	})
 }
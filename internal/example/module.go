@@ -35,6 +35,11 @@ func (m *Module) GetInfo() *module.Module {
 	}
 }
 
+// Provide exposes the example service to modules depending on Example
+func (m *Module) Provide() any {
+	return *m.Usecase
+}
+
 func (m *Module) RegisterHTTP() {
 	if m.Delivery.HTTP == nil {
 		panic("router is nil")
@@ -0,0 +1,153 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+)
+
+// Kind classifies an application error independently of the transport it is reported over
+type Kind string
+
+const (
+	KindInternal        Kind = "internal"
+	KindBadRequest      Kind = "bad_request"
+	KindUnauthorized    Kind = "unauthorized"
+	KindForbidden       Kind = "forbidden"
+	KindNotFound        Kind = "not_found"
+	KindConflict        Kind = "conflict"
+	KindValidation      Kind = "validation"
+	KindTooManyRequests Kind = "too_many_requests"
+	KindUnavailable     Kind = "unavailable"
+)
+
+var statuses = map[Kind]int{
+	KindInternal:        http.StatusInternalServerError,
+	KindBadRequest:      http.StatusBadRequest,
+	KindUnauthorized:    http.StatusUnauthorized,
+	KindForbidden:       http.StatusForbidden,
+	KindNotFound:        http.StatusNotFound,
+	KindConflict:        http.StatusConflict,
+	KindValidation:      http.StatusUnprocessableEntity,
+	KindTooManyRequests: http.StatusTooManyRequests,
+	KindUnavailable:     http.StatusServiceUnavailable,
+}
+
+// Status returns the HTTP status of the kind, an unknown kind is an internal error
+func (k Kind) Status() int {
+	if status, ok := statuses[k]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is an error with a kind, a message safe to show to clients and an optional cause
+type Error struct {
+	Kind    Kind
+	Code    string            // optional machine readable code, the kind is used when empty
+	Message string            // shown to clients
+	Fields  validation.Errors // per-field problems of a validation error
+	Err     error             // the cause, never shown to clients outside development
+}
+
+// New creates an error of the given kind
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Newf creates an error of the given kind with a formatted message
+func Newf(kind Kind, format string, args ...any) *Error {
+	return New(kind, fmt.Sprintf(format, args...))
+}
+
+// Wrap creates an error of the given kind caused by err
+func Wrap(err error, kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode sets the machine readable code
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+// Status returns the HTTP status of the error kind
+func (e *Error) Status() int {
+	return e.Kind.Status()
+}
+
+func BadRequest(message string) *Error {
+	return New(KindBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+func TooManyRequests(message string) *Error {
+	return New(KindTooManyRequests, message)
+}
+
+func Unavailable(message string) *Error {
+	return New(KindUnavailable, message)
+}
+
+// Internal wraps an unexpected error, its message is hidden from clients outside development
+func Internal(err error) *Error {
+	return Wrap(err, KindInternal, "internal server error")
+}
+
+// Validation creates a validation error listing the per-field problems
+func Validation(fields validation.Errors) *Error {
+	return &Error{Kind: KindValidation, Message: "the given data is invalid", Fields: fields}
+}
+
+// From converts any error to an *Error: application errors are returned as is,
+// validation.Errors become validation errors and everything else is internal
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return Validation(fields)
+	}
+	return Internal(err)
+}
+
+// KindOf returns the kind of err, KindInternal when it is not an application error
+func KindOf(err error) Kind {
+	return From(err).Kind
+}
+
+// Is reports whether err is an application error of the given kind
+func Is(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
@@ -0,0 +1,35 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+)
+
+func TestFrom(t *testing.T) {
+	cause := errors.New("duplicate entry")
+	tests := []struct {
+		err    error
+		kind   Kind
+		status int
+	}{
+		{fmt.Errorf("create user: %w", Wrap(cause, KindConflict, "email already taken")), KindConflict, 409},
+		{validation.Errors{{Field: "name", Message: "can't be empty"}}, KindValidation, 422},
+		{cause, KindInternal, 500},
+	}
+	for _, tt := range tests {
+		appErr := From(tt.err)
+		if appErr.Kind != tt.kind || appErr.Status() != tt.status {
+			t.Errorf("Expected %s %d for %v, got %s %d", tt.kind, tt.status, tt.err, appErr.Kind, appErr.Status())
+		}
+	}
+
+	if !errors.Is(Internal(cause), cause) {
+		t.Error("Expected an internal error to unwrap to its cause")
+	}
+	if !Is(fmt.Errorf("wrapped: %w", NotFound("missing")), KindNotFound) {
+		t.Error("Expected Is to find a wrapped not found error")
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// APIKeyHeader is the header an API key is read from by default
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyPrefix starts every generated key, so leaked keys are easy to scan for
+const APIKeyPrefix = "gsk_"
+
+// APIKeyStore looks up API keys by the hash of the key, the key itself is never stored
+type APIKeyStore interface {
+	// Lookup returns the owner of a key, ErrInvalidCredentials when the key is unknown, expired or revoked
+	Lookup(ctx context.Context, hash string) (*Principal, error)
+	// Save stores a key for the principal, a zero expiresAt never expires
+	Save(ctx context.Context, hash string, p *Principal, expiresAt time.Time) error
+	// Revoke invalidates a key, revoking an unknown key is not an error
+	Revoke(ctx context.Context, hash string) error
+}
+
+// GenerateAPIKey returns a new random key to hand out once and the hash to save in the store
+func GenerateAPIKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("auth: generate api key: %w", err)
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 of a key
+// Keys are long and random, so a fast unsalted hash is enough and allows lookups by hash
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKey authenticates requests by an API key header
+type APIKey struct {
+	store  APIKeyStore
+	header string
+}
+
+// NewAPIKey creates an API key authenticator, the key is read from header or APIKeyHeader when empty
+func NewAPIKey(store APIKeyStore, header string) *APIKey {
+	if header == "" {
+		header = APIKeyHeader
+	}
+	return &APIKey{store: store, header: header}
+}
+
+// Authenticate looks the hash of the key up in the store
+func (a *APIKey) Authenticate(ctx context.Context, header Header) (*Principal, error) {
+	key := header(a.header)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	p, err := a.store.Lookup(ctx, HashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("auth: look up api key: %w", err)
+	}
+	p.Method = MethodAPIKey
+	return p, nil
+}
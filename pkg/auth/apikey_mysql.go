@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// MySQLAPIKeySchema creates the table MySQLAPIKeyStore reads, run it in a migration
+const MySQLAPIKeySchema = `CREATE TABLE IF NOT EXISTS api_keys (
+	key_hash     CHAR(64)     NOT NULL PRIMARY KEY,
+	principal_id VARCHAR(255) NOT NULL,
+	name         VARCHAR(255) NOT NULL DEFAULT '',
+	key_groups   VARCHAR(1024) NOT NULL DEFAULT '',
+	expires_at   DATETIME     NULL,
+	revoked_at   DATETIME     NULL,
+	created_at   DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_api_keys_principal (principal_id)
+)`
+
+// MySQLAPIKeyStore keeps API keys in the api_keys table, see MySQLAPIKeySchema
+// Groups are stored comma separated
+type MySQLAPIKeyStore struct {
+	db *sql.DB
+}
+
+// NewMySQLAPIKeyStore creates an API key store on db
+func NewMySQLAPIKeyStore(db *sql.DB) *MySQLAPIKeyStore {
+	return &MySQLAPIKeyStore{db: db}
+}
+
+// Lookup returns the owner of a key that is neither expired nor revoked
+func (s *MySQLAPIKeyStore) Lookup(ctx context.Context, hash string) (*Principal, error) {
+	var p Principal
+	var groups string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT principal_id, name, key_groups FROM api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)`,
+		hash, time.Now().UTC(),
+	).Scan(&p.ID, &p.Name, &groups)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Groups = splitGroups(groups)
+	return &p, nil
+}
+
+// Save inserts a key, or replaces the owner of an existing one
+func (s *MySQLAPIKeyStore) Save(ctx context.Context, hash string, p *Principal, expiresAt time.Time) error {
+	var expires sql.NullTime
+	if !expiresAt.IsZero() {
+		expires = sql.NullTime{Time: expiresAt.UTC(), Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO api_keys (key_hash, principal_id, name, key_groups, expires_at) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE principal_id = VALUES(principal_id), name = VALUES(name),
+		key_groups = VALUES(key_groups), expires_at = VALUES(expires_at), revoked_at = NULL`,
+		hash, p.ID, p.Name, strings.Join(p.Groups, ","), expires,
+	)
+	return err
+}
+
+// Revoke marks a key as revoked, the row is kept for auditing
+func (s *MySQLAPIKeyStore) Revoke(ctx context.Context, hash string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = ? WHERE key_hash = ? AND revoked_at IS NULL`,
+		time.Now().UTC(), hash,
+	)
+	return err
+}
+
+func splitGroups(s string) []string {
+	var groups []string
+	for _, g := range strings.Split(s, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisAPIKeyPrefix starts the redis key of every API key hash
+const RedisAPIKeyPrefix = "auth:apikey:"
+
+// RedisAPIKeyStore keeps every API key in a hash at RedisAPIKeyPrefix + key hash, expired keys are removed by redis
+type RedisAPIKeyStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisAPIKeyStore creates an API key store on client
+func NewRedisAPIKeyStore(client redis.UniversalClient) *RedisAPIKeyStore {
+	return &RedisAPIKeyStore{client: client}
+}
+
+// Lookup returns the owner of a stored key
+func (s *RedisAPIKeyStore) Lookup(ctx context.Context, hash string) (*Principal, error) {
+	fields, err := s.client.HGetAll(ctx, RedisAPIKeyPrefix+hash).Result()
+	if err != nil {
+		return nil, err
+	}
+	if fields["principal_id"] == "" {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{
+		ID:     fields["principal_id"],
+		Name:   fields["name"],
+		Groups: splitGroups(fields["groups"]),
+	}, nil
+}
+
+// Save stores a key, it expires at expiresAt unless that is zero
+func (s *RedisAPIKeyStore) Save(ctx context.Context, hash string, p *Principal, expiresAt time.Time) error {
+	key := RedisAPIKeyPrefix + hash
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "principal_id", p.ID, "name", p.Name, "groups", strings.Join(p.Groups, ","))
+		if !expiresAt.IsZero() {
+			pipe.ExpireAt(ctx, key, expiresAt)
+		}
+		return nil
+	})
+	return err
+}
+
+// Revoke deletes a key
+func (s *RedisAPIKeyStore) Revoke(ctx context.Context, hash string) error {
+	return s.client.Del(ctx, RedisAPIKeyPrefix+hash).Err()
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type memoryStore map[string]*Principal
+
+func (s memoryStore) Lookup(_ context.Context, hash string) (*Principal, error) {
+	p, ok := s[hash]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (s memoryStore) Save(_ context.Context, hash string, p *Principal, _ time.Time) error {
+	s[hash] = p
+	return nil
+}
+
+func (s memoryStore) Revoke(_ context.Context, hash string) error {
+	delete(s, hash)
+	return nil
+}
+
+type failingStore struct{ memoryStore }
+
+func (failingStore) Lookup(context.Context, string) (*Principal, error) {
+	return nil, errors.New("connection refused")
+}
+
+func headers(values map[string]string) Header {
+	return func(key string) string { return values[key] }
+}
+
+func TestAPIKey(t *testing.T) {
+	key, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey failed: %v", err)
+	}
+	if !strings.HasPrefix(key, APIKeyPrefix) || hash != HashAPIKey(key) || strings.Contains(hash, key) {
+		t.Errorf("Expected a prefixed key and its hash, got %q and %q", key, hash)
+	}
+
+	store := memoryStore{}
+	_ = store.Save(context.Background(), hash, &Principal{ID: "service-1", Groups: []string{"ingest"}}, time.Time{})
+	a := NewAPIKey(store, "")
+
+	p, err := a.Authenticate(context.Background(), headers(map[string]string{APIKeyHeader: key}))
+	if err != nil {
+		t.Fatalf("Expected a principal, got %v", err)
+	}
+	if p.ID != "service-1" || p.Method != MethodAPIKey || !p.InGroup("ingest") {
+		t.Errorf("Expected service-1 in ingest authenticated by api key, got %+v", p)
+	}
+
+	if _, err := a.Authenticate(context.Background(), headers(map[string]string{APIKeyHeader: "gsk_unknown"})); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown key, got %v", err)
+	}
+	if _, err := a.Authenticate(context.Background(), headers(nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials without header, got %v", err)
+	}
+
+	_ = store.Revoke(context.Background(), hash)
+	if _, err := a.Authenticate(context.Background(), headers(map[string]string{APIKeyHeader: key})); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a revoked key, got %v", err)
+	}
+
+	_, err = NewAPIKey(failingStore{}, "").Authenticate(context.Background(), headers(map[string]string{APIKeyHeader: key}))
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected a store error apart from invalid credentials, got %v", err)
+	}
+}
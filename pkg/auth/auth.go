@@ -0,0 +1,72 @@
+// Package auth authenticates requests with JWTs, API keys or HTTP basic auth
+// An Authenticator turns the credentials of a request into a Principal, Chain tries several in order
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries none of the credentials an authenticator reads,
+	// a Chain then tries the next authenticator
+	ErrNoCredentials = errors.New("auth: no credentials")
+	// ErrInvalidCredentials is returned when the credentials are present but wrong, expired or revoked
+	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+)
+
+// Header reads a request header by name, like fiber.Ctx.Get or http.Header.Get
+type Header func(key string) string
+
+// Authenticator resolves the principal of a request from its headers
+// It returns ErrNoCredentials when its credentials are missing and ErrInvalidCredentials when they are wrong,
+// any other error is a failure of the authenticator itself, like an unreachable key store
+type Authenticator interface {
+	Authenticate(ctx context.Context, header Header) (*Principal, error)
+}
+
+// Challenger is implemented by authenticators that tell the client how to authenticate,
+// its value is sent in the WWW-Authenticate header of a 401 response
+type Challenger interface {
+	Challenge() string
+}
+
+// Chain tries each authenticator in order until one finds its credentials
+type Chain []Authenticator
+
+// Authenticate returns the result of the first authenticator that does not return ErrNoCredentials
+func (c Chain) Authenticate(ctx context.Context, header Header) (*Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(ctx, header)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// Challenge joins the challenges of the authenticators in the chain
+func (c Chain) Challenge() string {
+	var challenges []string
+	for _, a := range c {
+		if ch, ok := a.(Challenger); ok && ch.Challenge() != "" {
+			challenges = append(challenges, ch.Challenge())
+		}
+	}
+	return strings.Join(challenges, ", ")
+}
+
+// bearerToken returns the token of an Authorization: Bearer header, empty when there is none
+func bearerToken(header Header) string {
+	return schemeValue(header("Authorization"), "Bearer")
+}
+
+// schemeValue returns the value after the scheme of an Authorization header, the scheme is case insensitive
+func schemeValue(authorization, scheme string) string {
+	if len(authorization) <= len(scheme) || !strings.EqualFold(authorization[:len(scheme)], scheme) || authorization[len(scheme)] != ' ' {
+		return ""
+	}
+	return strings.TrimSpace(authorization[len(scheme)+1:])
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicUser is a user of basic auth
+type BasicUser struct {
+	PasswordHash string // bcrypt hash of the password, see HashPassword
+	Groups       []string
+}
+
+// Basic authenticates requests by an Authorization: Basic header against a fixed set of users
+// Meant for internal tools and machine clients, prefer JWT for end users
+type Basic struct {
+	realm string
+	users map[string]BasicUser
+}
+
+// dummyHash is compared against for unknown users, so they take as long as a wrong password
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// NewBasic creates a basic auth authenticator for the users keyed by username
+func NewBasic(realm string, users map[string]BasicUser) *Basic {
+	return &Basic{realm: realm, users: users}
+}
+
+// HashPassword returns the bcrypt hash of a password to use as BasicUser.PasswordHash
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("auth: hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Authenticate checks the username and password, the username is the principal ID
+func (b *Basic) Authenticate(_ context.Context, header Header) (*Principal, error) {
+	encoded := schemeValue(header("Authorization"), "Basic")
+	if encoded == "" {
+		return nil, ErrNoCredentials
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed basic auth header", ErrInvalidCredentials)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed basic auth header", ErrInvalidCredentials)
+	}
+
+	user, known := b.users[username]
+	hash := dummyHash()
+	if known {
+		hash = []byte(user.PasswordHash)
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !known {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{ID: username, Name: username, Method: MethodBasic, Groups: user.Groups}, nil
+}
+
+// Challenge asks for a username and password, browsers show a login prompt for it
+func (b *Basic) Challenge() string {
+	return fmt.Sprintf("Basic realm=%q", b.realm)
+}
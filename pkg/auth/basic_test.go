@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func basic(credentials string) Header {
+	return headers(map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))})
+}
+
+func TestBasic(t *testing.T) {
+	hash, err := HashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	b := NewBasic("ops", map[string]BasicUser{"ops": {PasswordHash: hash, Groups: []string{"admin"}}})
+
+	p, err := b.Authenticate(context.Background(), basic("ops:s3cret"))
+	if err != nil {
+		t.Fatalf("Expected a principal, got %v", err)
+	}
+	if p.ID != "ops" || p.Method != MethodBasic || !p.InGroup("admin") {
+		t.Errorf("Expected ops in admin authenticated by basic auth, got %+v", p)
+	}
+
+	for _, credentials := range []string{"ops:wrong", "nobody:s3cret", "ops"} {
+		if _, err := b.Authenticate(context.Background(), basic(credentials)); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials for %q, got %v", credentials, err)
+		}
+	}
+	if _, err := b.Authenticate(context.Background(), bearer("token")); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials for a bearer token, got %v", err)
+	}
+	if b.Challenge() != `Basic realm="ops"` {
+		t.Errorf("Expected a basic challenge, got %q", b.Challenge())
+	}
+}
+
+func TestChain(t *testing.T) {
+	hash, _ := HashPassword("s3cret")
+	j, err := NewJWT(JWTOptions{Algorithms: []string{"HS256"}, Keys: Keys{"": []byte("secret")}})
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+	chain := Chain{
+		j,
+		NewBasic("ops", map[string]BasicUser{"ops": {PasswordHash: hash}}),
+	}
+
+	p, err := chain.Authenticate(context.Background(), basic("ops:s3cret"))
+	if err != nil || p.Method != MethodBasic {
+		t.Errorf("Expected the basic authenticator to be tried after jwt, got %+v, %v", p, err)
+	}
+	if _, err := chain.Authenticate(context.Background(), bearer("broken")); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected an invalid bearer token to stop the chain, got %v", err)
+	}
+	if _, err := chain.Authenticate(context.Background(), headers(nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials without credentials, got %v", err)
+	}
+	if chain.Challenge() != `Bearer, Basic realm="ops"` {
+		t.Errorf("Expected both challenges, got %q", chain.Challenge())
+	}
+}
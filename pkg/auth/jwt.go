@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTAlgorithms lists the signing algorithms JWT accepts
+var JWTAlgorithms = []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// JWTOptions configures a JWT authenticator
+type JWTOptions struct {
+	Algorithms  []string      // accepted signing algorithms, never mix HS with RS or ES keys
+	Keys        Keys          // verification keys by key ID
+	Issuer      string        // required iss claim, not checked when empty
+	Audience    string        // required aud claim, not checked when empty
+	Leeway      time.Duration // clock skew allowed on exp, nbf and iat
+	GroupsClaim string        // claim holding the groups, a list or a space separated string, groups when empty
+}
+
+// JWT authenticates Authorization: Bearer tokens, every token must carry an exp claim
+type JWT struct {
+	opts   JWTOptions
+	parser *jwt.Parser
+}
+
+// NewJWT creates a JWT authenticator, it fails on an unknown algorithm or without keys
+func NewJWT(opts JWTOptions) (*JWT, error) {
+	if len(opts.Algorithms) == 0 {
+		return nil, errors.New("auth: jwt needs at least one algorithm")
+	}
+	for _, alg := range opts.Algorithms {
+		if !slices.Contains(JWTAlgorithms, alg) {
+			return nil, fmt.Errorf("auth: unsupported jwt algorithm %q", alg)
+		}
+	}
+	if len(opts.Keys) == 0 {
+		return nil, errors.New("auth: jwt needs at least one key")
+	}
+	if opts.GroupsClaim == "" {
+		opts.GroupsClaim = "groups"
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(opts.Algorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	return &JWT{opts: opts, parser: jwt.NewParser(parserOpts...)}, nil
+}
+
+// Authenticate verifies the bearer token and returns its subject as the principal
+func (j *JWT) Authenticate(_ context.Context, header Header) (*Principal, error) {
+	raw := bearerToken(header)
+	if raw == "" {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := j.parser.ParseWithClaims(raw, claims, j.key); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	sub, _ := claims.GetSubject()
+	if sub == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	name, _ := claims["name"].(string)
+	if name == "" {
+		name, _ = claims["preferred_username"].(string)
+	}
+	return &Principal{
+		ID:     sub,
+		Name:   name,
+		Method: MethodJWT,
+		Groups: claimStrings(claims[j.opts.GroupsClaim]),
+		Claims: claims,
+	}, nil
+}
+
+// Challenge asks for a bearer token
+func (j *JWT) Challenge() string {
+	return "Bearer"
+}
+
+// key picks the verification key by the kid header of the token
+// The key type is checked by the signing method, so an RS256 key can't verify an HS256 token
+func (j *JWT) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.opts.Keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+// claimStrings reads a claim that is a list of strings or a space separated string, like scope
+func claimStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func bearer(token string) Header {
+	return func(key string) string {
+		if key == "Authorization" {
+			return "Bearer " + token
+		}
+		return ""
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":    "user-1",
+		"name":   "Ada",
+		"iss":    "https://issuer.test",
+		"aud":    "api",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"groups": []string{"admin", "staff"},
+	}
+}
+
+func TestJWTHMAC(t *testing.T) {
+	secret := []byte("a very long test secret")
+	j, err := NewJWT(JWTOptions{Algorithms: []string{"HS256"}, Keys: Keys{"": secret}, Issuer: "https://issuer.test", Audience: "api"})
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+
+	p, err := j.Authenticate(context.Background(), bearer(sign(t, jwt.SigningMethodHS256, secret, "", validClaims())))
+	if err != nil {
+		t.Fatalf("Expected a principal, got %v", err)
+	}
+	if p.ID != "user-1" || p.Name != "Ada" || p.Method != MethodJWT {
+		t.Errorf("Expected user-1 Ada authenticated by jwt, got %+v", p)
+	}
+	if !p.InGroup("staff") || p.InGroup("billing") {
+		t.Errorf("Expected groups admin and staff, got %v", p.Groups)
+	}
+
+	tests := map[string]string{
+		"wrong secret":   sign(t, jwt.SigningMethodHS256, []byte("another secret"), "", validClaims()),
+		"other method":   sign(t, jwt.SigningMethodHS512, secret, "", validClaims()),
+		"expired":        sign(t, jwt.SigningMethodHS256, secret, "", jwt.MapClaims{"sub": "user-1", "iss": "https://issuer.test", "aud": "api", "exp": time.Now().Add(-time.Hour).Unix()}),
+		"no expiry":      sign(t, jwt.SigningMethodHS256, secret, "", jwt.MapClaims{"sub": "user-1", "iss": "https://issuer.test", "aud": "api"}),
+		"wrong audience": sign(t, jwt.SigningMethodHS256, secret, "", jwt.MapClaims{"sub": "user-1", "iss": "https://issuer.test", "aud": "other", "exp": time.Now().Add(time.Hour).Unix()}),
+		"unknown kid":    sign(t, jwt.SigningMethodHS256, secret, "rotated", validClaims()),
+		"garbage":        "not.a.token",
+	}
+	for name, token := range tests {
+		if _, err := j.Authenticate(context.Background(), bearer(token)); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: expected ErrInvalidCredentials, got %v", name, err)
+		}
+	}
+
+	if _, err := j.Authenticate(context.Background(), func(string) string { return "" }); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials without header, got %v", err)
+	}
+}
+
+func TestJWTRSAPublicKey(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePublicKey failed: %v", err)
+	}
+
+	j, err := NewJWT(JWTOptions{Algorithms: []string{"RS256"}, Keys: Keys{"": public}})
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+	if _, err := j.Authenticate(context.Background(), bearer(sign(t, jwt.SigningMethodRS256, private, "", validClaims()))); err != nil {
+		t.Errorf("Expected RS256 token to verify, got %v", err)
+	}
+
+	// the public key must not be usable as an HMAC secret
+	forged := sign(t, jwt.SigningMethodHS256, der, "", validClaims())
+	if _, err := j.Authenticate(context.Background(), bearer(forged)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected HS256 token to be rejected, got %v", err)
+	}
+}
+
+func TestJWTECDSAFromJWKS(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, err := private.PublicKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := base64.RawURLEncoding.EncodeToString
+	set, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": enc(point[1:33]), "y": enc(point[33:])},
+		{"kty": "RSA", "kid": "rsa-1", "n": enc(rsaKey.N.Bytes()), "e": "AQAB"},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "broken", "e": "AQAB"},
+	}})
+
+	keys, err := ParseJWKS(set)
+	if err != nil {
+		t.Fatalf("ParseJWKS failed: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("Expected 2 signing keys, got %d", len(keys))
+	}
+
+	j, err := NewJWT(JWTOptions{Algorithms: []string{"ES256", "RS256"}, Keys: keys})
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+	if _, err := j.Authenticate(context.Background(), bearer(sign(t, jwt.SigningMethodES256, private, "ec-1", validClaims()))); err != nil {
+		t.Errorf("Expected ES256 token to verify, got %v", err)
+	}
+	if _, err := j.Authenticate(context.Background(), bearer(sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims()))); err != nil {
+		t.Errorf("Expected RS256 token to verify, got %v", err)
+	}
+	// signed by the EC key but pointing at the RSA key
+	if _, err := j.Authenticate(context.Background(), bearer(sign(t, jwt.SigningMethodES256, private, "rsa-1", validClaims()))); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected mismatched kid to be rejected, got %v", err)
+	}
+}
+
+func TestNewJWTRejectsUnknownAlgorithm(t *testing.T) {
+	if _, err := NewJWT(JWTOptions{Algorithms: []string{"none"}, Keys: Keys{"": []byte("secret")}}); err == nil {
+		t.Error("Expected an error for the none algorithm")
+	}
+}
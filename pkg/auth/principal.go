@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Method is the way a principal authenticated
+type Method string
+
+const (
+	MethodJWT    Method = "jwt"
+	MethodAPIKey Method = "api_key"
+	MethodBasic  Method = "basic"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID     string         // subject of the token, owner of the API key or the basic auth username
+	Name   string         // display name, may be empty
+	Method Method         // how the caller authenticated
+	Groups []string       // groups or roles used by RequireGroups
+	Claims map[string]any // every claim of a JWT, nil for the other methods
+}
+
+// InGroup reports whether the principal is in at least one of the groups
+func (p *Principal) InGroup(groups ...string) bool {
+	for _, group := range groups {
+		if slices.Contains(p.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal of ctx, false when the request is not authenticated
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Exit codes returned by Execute
+const (
+	ExitOK    = 0 // the command succeeded
+	ExitError = 1 // the command failed while running
+	ExitUsage = 2 // the command line is invalid
+)
+
+// Command is a node of the command tree
+// A command either runs something (Run) or groups subcommands, or both
+//
+// Example:
+//
+//	enqueue := &cli.Command{Name: "enqueue", Usage: "<task> [json payload]", Short: "Enqueue a task"}
+//	queueName := enqueue.Flags().String("queue", "", "queue name")
+//	enqueue.Run = func(ctx context.Context, args []string) error { ... }
+type Command struct {
+	Name        string // The name used on the command line
+	Usage       string // The arguments of the command, shown in help output
+	Short       string // One line description shown in the parent's command list
+	Run         func(ctx context.Context, args []string) error
+	Subcommands []*Command
+
+	flags *flag.FlagSet
+}
+
+// UsageError reports an invalid command line, Execute exits with ExitUsage for it
+type UsageError struct {
+	Message string
+}
+
+func (e *UsageError) Error() string {
+	return e.Message
+}
+
+// Usagef creates a UsageError with a formatted message
+func Usagef(format string, args ...any) error {
+	return &UsageError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Flags returns the flag set of the command, flags are parsed before Run is called
+func (c *Command) Flags() *flag.FlagSet {
+	if c.flags == nil {
+		c.flags = flag.NewFlagSet(c.Name, flag.ContinueOnError)
+		c.flags.SetOutput(io.Discard)
+	}
+	return c.flags
+}
+
+// Add appends subcommands to the command
+func (c *Command) Add(commands ...*Command) {
+	c.Subcommands = append(c.Subcommands, commands...)
+}
+
+// Find returns the direct subcommand with the given name
+func (c *Command) Find(name string) *Command {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Execute runs the command matching args and returns the process exit code
+// Errors are printed to stderr, help is printed to stdout when -h or help is requested
+func Execute(ctx context.Context, root *Command, args []string) int {
+	return execute(ctx, root, args, os.Stdout, os.Stderr)
+}
+
+func execute(ctx context.Context, root *Command, args []string, stdout, stderr io.Writer) int {
+	path := []string{root.Name}
+	cmd := root
+
+	for {
+		if err := cmd.Flags().Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				printHelp(stdout, cmd, path)
+				return ExitOK
+			}
+			return usageFailure(stderr, cmd, path, err)
+		}
+		args = cmd.Flags().Args()
+
+		if len(args) == 0 || len(cmd.Subcommands) == 0 {
+			break
+		}
+		if args[0] == "help" {
+			printHelp(stdout, cmd, path)
+			return ExitOK
+		}
+
+		sub := cmd.Find(args[0])
+		if sub == nil {
+			if cmd.Run != nil {
+				break
+			}
+			return usageFailure(stderr, cmd, path, Usagef("unknown command %q", args[0]))
+		}
+
+		cmd = sub
+		path = append(path, sub.Name)
+		args = args[1:]
+	}
+
+	if cmd.Run == nil {
+		printHelp(stdout, cmd, path)
+		return ExitUsage
+	}
+
+	if err := cmd.Run(ctx, args); err != nil {
+		var usageErr *UsageError
+		if errors.As(err, &usageErr) {
+			return usageFailure(stderr, cmd, path, err)
+		}
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return ExitError
+	}
+	return ExitOK
+}
+
+func usageFailure(w io.Writer, cmd *Command, path []string, err error) int {
+	_, _ = fmt.Fprintf(w, "Error: %v\n\n", err)
+	printHelp(w, cmd, path)
+	return ExitUsage
+}
+
+func printHelp(w io.Writer, cmd *Command, path []string) {
+	usage := strings.Join(path, " ")
+	if len(cmd.Subcommands) > 0 {
+		usage += " <command>"
+	}
+	if cmd.Usage != "" {
+		usage += " " + cmd.Usage
+	}
+
+	_, _ = fmt.Fprintf(w, "Usage: %s\n", usage)
+	if cmd.Short != "" {
+		_, _ = fmt.Fprintf(w, "\n%s\n", cmd.Short)
+	}
+
+	if len(cmd.Subcommands) > 0 {
+		_, _ = fmt.Fprintf(w, "\nCommands:\n")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, sub := range cmd.Subcommands {
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\n", sub.Name, sub.Short)
+		}
+		_ = tw.Flush()
+	}
+
+	hasFlags := false
+	cmd.Flags().VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		_, _ = fmt.Fprintf(w, "\nFlags:\n")
+		cmd.Flags().SetOutput(w)
+		cmd.Flags().PrintDefaults()
+		cmd.Flags().SetOutput(io.Discard)
+	}
+
+	if len(cmd.Subcommands) > 0 {
+		_, _ = fmt.Fprintf(w, "\nRun '%s <command> -h' for more information on a command.\n", strings.Join(path, " "))
+	}
+}
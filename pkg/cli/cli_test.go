@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestRoot(got *[]string) *Command {
+	enqueue := &Command{Name: "enqueue", Usage: "<task>"}
+	queue := enqueue.Flags().String("queue", "default", "queue name")
+	enqueue.Run = func(ctx context.Context, args []string) error {
+		if len(args) != 1 {
+			return Usagef("task is required")
+		}
+		*got = append(*got, *queue, args[0])
+		return nil
+	}
+
+	root := &Command{Name: "app"}
+	root.Add(
+		&Command{Name: "task", Subcommands: []*Command{enqueue}},
+		&Command{Name: "fail", Run: func(ctx context.Context, args []string) error {
+			return errors.New("boom")
+		}},
+	)
+	return root
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+		got  []string
+	}{
+		{name: "subcommand with flag", args: []string{"task", "enqueue", "-queue", "low", "email:send"}, code: ExitOK, got: []string{"low", "email:send"}},
+		{name: "usage error from run", args: []string{"task", "enqueue"}, code: ExitUsage},
+		{name: "unknown flag", args: []string{"task", "enqueue", "-bogus"}, code: ExitUsage},
+		{name: "unknown command", args: []string{"bogus"}, code: ExitUsage},
+		{name: "missing command", args: nil, code: ExitUsage},
+		{name: "run error", args: []string{"fail"}, code: ExitError},
+		{name: "help", args: []string{"task", "-h"}, code: ExitOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			var stdout, stderr bytes.Buffer
+			code := execute(context.Background(), newTestRoot(&got), tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tt.code, code, stderr.String())
+			}
+			if !reflect.DeepEqual(got, tt.got) {
+				t.Errorf("Expected %v, got %v", tt.got, got)
+			}
+		})
+	}
+}
+
+func TestHelpListsSubcommands(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	execute(context.Background(), newTestRoot(new([]string)), []string{"help"}, &stdout, &stderr)
+
+	if !strings.Contains(stdout.String(), "task") || !strings.Contains(stdout.String(), "fail") {
+		t.Errorf("Expected help to list subcommands, got %s", stdout.String())
+	}
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+)
+
+// Struct tags read by Bind
+const (
+	TagEnv      = "env"      // name of the environment variable, e.g. `env:"DB_HOST"`
+	TagDefault  = "default"  // value used when the variable is unset or empty, e.g. `default:"localhost"`
+	TagValidate = "validate" // pkg/validation rules checked after binding, e.g. `validate:"nummin=1,nummax=65535"`
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	fileModeType = reflect.TypeOf(os.FileMode(0))
+)
+
+// Bind populates target, a pointer to a struct, from environment variables using the `env`,
+// `default` and `validate` field tags. Nested structs and struct pointers are bound recursively,
+// nil pointers are allocated
+//
+// Supported field types: string, bool, ints, uints, floats, time.Duration ("30s"),
+// os.FileMode (octal, "0755"), slices (comma-separated, "a,b") and maps (comma-separated pairs, "a=1,b=2")
+//
+// Values like file:///run/secrets/db_password or env://OTHER_VAR are resolved as secret references
+//
+// Every value that fails to parse or validate is collected and returned as an *Error
+func Bind(target any) error {
+	l := layers{processEnv()}
+	return bind(target, &binder{
+		layers:   l,
+		resolver: newSecretResolver(l, nil),
+	})
+}
+
+// bind populates target with the binder
+// An empty value is treated as unset, so an empty value in an env file falls back to the default
+func bind(target any, b *binder) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: bind target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	b.bindStruct(v.Elem())
+	if b.errs.HasErrors() {
+		return &Error{Problems: b.errs}
+	}
+	return nil
+}
+
+type binder struct {
+	ctx      context.Context
+	layers   layers
+	resolver *secretResolver
+	sources  map[string]string // where each key came from, recorded when not nil
+	secrets  map[string]bool   // keys resolved from a secret reference, recorded when not nil
+	errs     validation.Errors
+}
+
+func (b *binder) record(key, source string) {
+	if b.sources != nil {
+		b.sources[key] = source
+	}
+}
+
+// resolveSecret resolves raw when it is a secret reference
+func (b *binder) resolveSecret(key, raw string) (string, error) {
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	value, isRef, err := b.resolver.resolve(ctx, raw)
+	if isRef && b.secrets != nil {
+		b.secrets[key] = true
+	}
+	return value, err
+}
+
+func (b *binder) bindStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		key := field.Tag.Get(TagEnv)
+		if key == "" {
+			// untagged structs and struct pointers hold nested settings
+			switch {
+			case fv.Kind() == reflect.Struct:
+				b.bindStruct(fv)
+			case fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct:
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				b.bindStruct(fv.Elem())
+			}
+			continue
+		}
+
+		raw, source, ok := b.layers.lookup(key)
+		if !ok {
+			raw, ok = field.Tag.Lookup(TagDefault)
+			source = SourceDefault
+		}
+		if !ok {
+			source = SourceUnset
+		}
+		b.record(key, source)
+
+		if ok && raw == "" {
+			// set to nothing on the command line, which clears the default too
+			fv.Set(reflect.Zero(fv.Type()))
+		} else if ok {
+			resolved, err := b.resolveSecret(key, raw)
+			if err != nil {
+				b.errs = append(b.errs, validation.Error{Field: key, Message: err.Error()})
+				continue
+			}
+			// raw is the reference for a resolved secret, so the message never echoes the secret itself
+			if err := setValue(fv, resolved); err != nil {
+				b.errs = append(b.errs, validation.Error{
+					Field:   key,
+					Message: fmt.Sprintf("%s, got %q", err.Error(), raw),
+				})
+				continue
+			}
+		}
+
+		if rules := field.Tag.Get(TagValidate); rules != "" {
+			if err := validation.Validate(key, fv.Interface(), rules); err != nil {
+				b.errs = append(b.errs, *err)
+			}
+		}
+	}
+}
+
+// setValue parses raw into v according to v's type
+func setValue(v reflect.Value, raw string) error {
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("must be a duration like 30s or 5m")
+		}
+		v.SetInt(int64(d))
+		return nil
+	case fileModeType:
+		mode, err := strconv.ParseUint(raw, 8, 32)
+		if err != nil {
+			return fmt.Errorf("must be an octal file mode like 0755")
+		}
+		v.SetUint(mode)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		v.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s", validation.ErrorMessageInt)
+		}
+		v.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive integer")
+		}
+		v.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s", validation.ErrorMessageFloat)
+		}
+		v.SetFloat(parsed)
+	case reflect.Slice:
+		items := splitList(raw)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), item); err != nil {
+				return fmt.Errorf("item %d %s", i, err.Error())
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, pair := range splitList(raw) {
+			k, val, found := strings.Cut(pair, "=")
+			if !found {
+				return fmt.Errorf("must be a list of key=value pairs")
+			}
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := setValue(key, strings.TrimSpace(k)); err != nil {
+				return fmt.Errorf("key %q %s", k, err.Error())
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, strings.TrimSpace(val)); err != nil {
+				return fmt.Errorf("value of %q %s", k, err.Error())
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("has unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// splitList splits a comma-separated value and drops empty items
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type bindNested struct {
+	Mode os.FileMode `env:"TEST_MODE" default:"0640"`
+}
+
+type bindTarget struct {
+	Name    string         `env:"TEST_NAME" default:"app"`
+	Port    int            `env:"TEST_PORT" default:"80" validate:"nummin=1,nummax=65535"`
+	Timeout time.Duration  `env:"TEST_TIMEOUT" default:"5s"`
+	Hosts   []string       `env:"TEST_HOSTS"`
+	Weights map[string]int `env:"TEST_WEIGHTS"`
+	Nested  *bindNested
+	Labels  map[string]string `env:"TEST_LABELS" default:"team=core"`
+}
+
+func TestBind(t *testing.T) {
+	t.Setenv("TEST_PORT", "8080")
+	t.Setenv("TEST_HOSTS", "a.local, b.local")
+	t.Setenv("TEST_WEIGHTS", "critical=6,default=3")
+
+	var target bindTarget
+	if err := Bind(&target); err != nil {
+		t.Fatalf("Failed to bind: %v", err)
+	}
+
+	expected := bindTarget{
+		Name:    "app",
+		Port:    8080,
+		Timeout: 5 * time.Second,
+		Hosts:   []string{"a.local", "b.local"},
+		Weights: map[string]int{"critical": 6, "default": 3},
+		Nested:  &bindNested{Mode: 0640},
+		Labels:  map[string]string{"team": "core"},
+	}
+	if !reflect.DeepEqual(target, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, target)
+	}
+}
+
+func TestBindCollectsErrors(t *testing.T) {
+	t.Setenv("TEST_PORT", "70000")
+	t.Setenv("TEST_TIMEOUT", "soon")
+	t.Setenv("TEST_MODE", "rwx")
+	t.Setenv("TEST_WEIGHTS", "critical")
+
+	var target bindTarget
+	var cfgErr *Error
+	if err := Bind(&target); !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	for _, env := range []string{"TEST_PORT", "TEST_TIMEOUT", "TEST_MODE", "TEST_WEIGHTS"} {
+		if len(cfgErr.Problems.ForField(env)) == 0 {
+			t.Errorf("Expected a problem for %s, got %v", env, cfgErr.Problems)
+		}
+	}
+}
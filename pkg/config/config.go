@@ -1,88 +1,113 @@
 package config
 
 import (
-	"time"
+	"errors"
+	"os"
 
-	"github.com/fatkulnurk/gostarter/pkg/support"
+	"github.com/fatkulnurk/gostarter/pkg/validation"
 	"github.com/fatkulnurk/gostarter/shared/constant"
-	"github.com/joho/godotenv"
 )
 
-func New(env string) *Config {
+// Options selects the sources Load reads configuration from
+type Options struct {
+	// Environment selects the .env.<environment> file, development when empty
+	Environment string
+	// File is an optional YAML, JSON or TOML config file, CONFIG_FILE is used when empty
+	File string
+	// Flags are values set on the command line, keyed by environment variable name
+	Flags map[string]string
+	// SecretProviders resolve secret references of additional schemes, file:// and env:// are built in
+	SecretProviders []SecretProvider
+}
+
+// New loads the configuration of the given environment, see Load
+func New(env string) (*Config, error) {
+	return Load(Options{Environment: env})
+}
+
+// Load builds the configuration from every source, a later source overrides an earlier one:
+//
+//	struct tag defaults < config file < .env.<environment> < process environment < command line flags
+//
+// In development the legacy .env file is read before .env.development
+// Missing env files are not an error, a missing config file is
+// Any value may be a secret reference like file:///run/secrets/db_password or env://OTHER_VAR
+// It returns an *Error listing every value that failed to parse or validate
+func Load(opts Options) (*Config, error) {
 	// default environment is development
-	envFile := ""
+	env := opts.Environment
 	if env == "" {
 		env = constant.EnvironmentDevelopment
 	}
 
-	switch env {
-	case constant.EnvironmentDevelopment:
-		envFile = ".env"
-	case constant.EnvironmentTest:
-		envFile = ".env.test"
+	var sources layers
+	var files []string
+
+	file := opts.File
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file != "" {
+		l, err := readConfigFile(file)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, l)
+		files = append(files, file)
 	}
 
-	// load environment variables
-	if err := godotenv.Load(envFile); err != nil {
-		panic(err)
+	envFiles := []string{".env." + env}
+	if env == constant.EnvironmentDevelopment {
+		envFiles = append([]string{".env"}, envFiles...)
 	}
+	for _, envFile := range envFiles {
+		l, err := readEnvFile(envFile)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, l)
+		files = append(files, envFile)
+	}
+
+	sources = append(sources, processEnv(), layer{name: SourceFlag, values: opts.Flags})
 
 	cfg := Config{
-		App: &App{
-			Environment: env,
-			Name:        support.GetEnv("APP_NAME", "GoStarter"),
-			Version:     support.GetEnv("APP_VERSION", "1.0.0"),
-		},
-		Database: &Database{
-			User:            support.GetEnv("DB_USER", "root"),
-			Password:        support.GetEnv("DB_PASSWORD", ""),
-			Host:            support.GetEnv("DB_HOST", "localhost"),
-			Port:            support.GetIntEnv("DB_PORT", 3306),
-			Database:        support.GetEnv("DB_NAME", "gostarter"),
-			Params:          support.GetEnv("DB_PARAMS", "charset=utf8mb4&parseTime=true"),
-			MaxOpenConns:    support.GetIntEnv("DB_MAX_OPEN_CONNS", 10),
-			MaxIdleConns:    support.GetIntEnv("DB_MAX_IDLE_CONNS", 5),
-			ConnMaxLifetime: support.GetDurationEnv("DB_CONN_MAX_LIFETIME", time.Hour),
-			ConnMaxIdleTime: support.GetDurationEnv("DB_CONN_MAX_IDLE_TIME", time.Minute*30),
-		},
-		DeliveryHttp: &DeliveryHttp{
-			Prefork:       support.GetBoolEnv("HTTP_PREFORK", false),
-			CaseSensitive: support.GetBoolEnv("HTTP_CASE_SENSITIVE", true),
-			StrictRouting: support.GetBoolEnv("HTTP_STRICT_ROUTING", false),
-			BodyLimit:     support.GetIntEnv("HTTP_BODY_LIMIT", 10*1024*1024),
-			ServerHeader:  support.GetEnv("HTTP_SERVER_HEADER", "GoStarter"),
-		},
-		DeliveryQueue: &DeliveryQueue{
-			Concurrency: support.GetIntEnv("QUEUE_CONCURRENCY", 10),
-		},
-		Redis: &Redis{
-			Addr:            support.GetEnv("REDIS_ADDR", "localhost:6379"),
-			Password:        support.GetEnv("REDIS_PASSWORD", ""),
-			DB:              support.GetIntEnv("REDIS_DB", 0),
-			PoolSize:        support.GetIntEnv("REDIS_POOL_SIZE", 10),
-			MinIdleConns:    support.GetIntEnv("REDIS_MIN_IDLE_CONNS", 5),
-			ConnMaxLifetime: support.GetDurationEnv("REDIS_CONN_MAX_LIFETIME", time.Hour),
-			PoolTimeout:     support.GetDurationEnv("REDIS_POOL_TIMEOUT", time.Second*4),
-			ConnMaxIdleTime: support.GetDurationEnv("REDIS_CONN_MAX_IDLE_TIME", time.Minute*30),
-			ReadTimeout:     support.GetDurationEnv("REDIS_READ_TIMEOUT", time.Second*3),
-			WriteTimeout:    support.GetDurationEnv("REDIS_WRITE_TIMEOUT", time.Second*3),
-			DialTimeout:     support.GetDurationEnv("REDIS_DIAL_TIMEOUT", time.Second*5),
-		},
-		Queue: &Queue{
-			Concurrency: support.GetIntEnv("QUEUE_WORKER_CONCURRENCY", 10),
-		},
-		Schedule: &Schedule{
-			Timezone: support.GetEnv("SCHEDULE_TIMEZONE", "UTC"),
-		},
-		SMTP: &SMTP{
-			Host:              support.GetEnv("SMTP_HOST", "smtp.gmail.com"),
-			Port:              support.GetIntEnv("SMTP_PORT", 587),
-			Username:          support.GetEnv("SMTP_USERNAME", ""),
-			Password:          support.GetEnv("SMTP_PASSWORD", ""),
-			AuthType:          support.GetEnv("SMTP_AUTH_TYPE", "PLAIN"),
-			WithTLSPortPolicy: support.GetIntEnv("SMTP_WITH_TLS_PORT_POLICY", 0),
-		},
+		sources: make(map[string]string),
+		secrets: make(map[string]bool),
+		opts:    opts,
+		files:   files,
 	}
+	bindErr := bind(&cfg, &binder{
+		layers:   sources,
+		resolver: newSecretResolver(sources, opts.SecretProviders),
+		sources:  cfg.sources,
+		secrets:  cfg.secrets,
+	})
+	cfg.App.Environment = env
 
-	return &cfg
+	// values that failed to parse hold their default, so validating still reports every other problem
+	var problems validation.Errors
+	var cfgErr *Error
+	if errors.As(bindErr, &cfgErr) {
+		problems = append(problems, cfgErr.Problems...)
+	} else if bindErr != nil {
+		return nil, bindErr
+	}
+	if errors.As(cfg.Validate(), &cfgErr) {
+		problems = append(problems, cfgErr.Problems...)
+	}
+	if problems.HasErrors() {
+		return nil, &Error{Problems: problems}
+	}
+
+	return &cfg, nil
+}
+
+// Source returns where the value of an environment variable came from:
+// a config or env file path, SourceEnv, SourceFlag, SourceDefault or SourceUnset
+func (c *Config) Source(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceUnset
 }
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/shared/constant"
+)
+
+func TestNewWithoutEnvFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cfg, err := New(constant.EnvironmentDevelopment)
+	if err != nil {
+		t.Fatalf("Expected defaults without env file, got %v", err)
+	}
+	if cfg.Database.Port != 3306 {
+		t.Errorf("Expected default DB_PORT 3306, got %d", cfg.Database.Port)
+	}
+}
+
+func TestNewReportsEveryProblem(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("DB_PORT", "abc")
+	t.Setenv("REDIS_POOL_TIMEOUT", "soon")
+	t.Setenv("SMTP_PORT", "70000")
+	t.Setenv("SCHEDULE_TIMEZONE", "Mars/Olympus")
+
+	_, err := New(constant.EnvironmentDevelopment)
+
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	for _, env := range []string{"DB_PORT", "REDIS_POOL_TIMEOUT", "SMTP_PORT", "SCHEDULE_TIMEZONE"} {
+		if len(cfgErr.Problems.ForField(env)) == 0 {
+			t.Errorf("Expected a problem for %s, got %v", env, cfgErr.Problems)
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	files := map[string]string{
+		"app.toml":         "DB_HOST = \"file\"\nDB_PORT = 3301\nDB_NAME = \"file\"\n[redis]\naddr = \"file:6379\"\n",
+		".env.staging":     "DB_PORT=3302\nDB_NAME=envfile\n",
+		".env.development": "DB_PORT=9999\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("DB_NAME", "process")
+	t.Setenv("REDIS_ADDR", "")
+
+	cfg, err := Load(Options{
+		Environment: constant.EnvironmentStaging,
+		File:        "app.toml",
+		Flags:       map[string]string{"SMTP_PORT": "2525"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	tests := []struct {
+		key, value, source string
+	}{
+		{"DB_HOST", "file", "app.toml"},
+		{"DB_PORT", "3302", ".env.staging"},
+		{"DB_NAME", "process", SourceEnv},
+		{"SMTP_PORT", "2525", SourceFlag},
+		{"DB_USER", "root", SourceDefault},
+	}
+	values := make(map[string]Entry)
+	for _, e := range cfg.Describe(true) {
+		values[e.Key] = e
+	}
+	for _, tt := range tests {
+		if e := values[tt.key]; e.Value != tt.value || e.Source != tt.source {
+			t.Errorf("Expected %s=%s from %s, got %s from %s", tt.key, tt.value, tt.source, e.Value, e.Source)
+		}
+	}
+
+	// nested keys are flattened, REDIS_ADDR set to an empty value does not override the file
+	if cfg.Redis.Addr != "file:6379" {
+		t.Errorf("Expected REDIS_ADDR from the nested redis table, got %s", cfg.Redis.Addr)
+	}
+}
+
+func TestConfigFileSections(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("database:\n  host: db.internal\n  port: 3307\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("SMTP_USERNAME=mailer\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(Options{File: "app.yaml", Flags: map[string]string{"SMTP_USERNAME": "", "DB_PARAMS": ""}})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Database.Host != "db.internal" || cfg.Database.Port != 3307 {
+		t.Errorf("Expected the database section to set DB_HOST and DB_PORT, got %s:%d", cfg.Database.Host, cfg.Database.Port)
+	}
+	// -set KEY= clears values from lower sources and defaults
+	if cfg.SMTP.Username != "" || cfg.Database.Params != "" {
+		t.Errorf("Expected empty flags to clear SMTP_USERNAME and DB_PARAMS, got %q and %q", cfg.SMTP.Username, cfg.Database.Params)
+	}
+}
+
+func TestConfigFileUnknownKey(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("database:\n  hosst: db.internal\nDB_PORT: 3307\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(Options{File: "app.yaml"})
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected a config error, got %v", err)
+	}
+	if len(cfgErr.Problems) != 1 || cfgErr.Problems[0].Field != "database.hosst" {
+		t.Errorf("Expected only database.hosst to be reported, got %v", cfgErr.Problems)
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("AUTH_JWT_ALGORITHMS", "HS256,RS256,none")
+	t.Setenv("AUTH_API_KEY_STORE", "postgres")
+	t.Setenv("AUTH_BASIC_USERS", "ops=$2a$10$hash")
+	t.Setenv("AUTH_BASIC_GROUPS", "ops=admin,ci=deploy")
+
+	_, err := New(constant.EnvironmentDevelopment)
+
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	for _, env := range []string{"AUTH_JWT_ALGORITHMS", "AUTH_JWT_SECRET", "AUTH_JWT_PUBLIC_KEY_FILE", "AUTH_API_KEY_STORE", "AUTH_BASIC_GROUPS"} {
+		if len(cfgErr.Problems.ForField(env)) == 0 {
+			t.Errorf("Expected a problem for %s, got %v", env, cfgErr.Problems)
+		}
+	}
+
+	t.Setenv("AUTH_JWT_ALGORITHMS", "RS256")
+	t.Setenv("AUTH_JWT_JWKS_FILE", "jwks.json")
+	t.Setenv("AUTH_API_KEY_STORE", "redis")
+	t.Setenv("AUTH_BASIC_GROUPS", "ops=admin")
+	if _, err := New(constant.EnvironmentDevelopment); err != nil {
+		t.Errorf("Expected a JWKS file to be enough for RS256, got %v", err)
+	}
+}
+
+func TestValidateProbePaths(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("METRICS_PATH", "/healthz")
+	t.Setenv("HEALTH_READINESS_PATH", "/healthz")
+
+	_, err := New(constant.EnvironmentDevelopment)
+
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	for _, env := range []string{"METRICS_PATH", "HEALTH_READINESS_PATH"} {
+		if len(cfgErr.Problems.ForField(env)) == 0 {
+			t.Errorf("Expected a problem for %s, got %v", env, cfgErr.Problems)
+		}
+	}
+
+	t.Setenv("METRICS_ENABLED", "false")
+	t.Setenv("HEALTH_READINESS_PATH", "/readyz")
+	if _, err := New(constant.EnvironmentDevelopment); err != nil {
+		t.Errorf("Expected METRICS_PATH to be free when metrics are disabled, got %v", err)
+	}
+}
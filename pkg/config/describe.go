@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces secret values in Describe output
+const RedactedValue = "******"
+
+// Entry is one effective configuration value with the source it came from
+type Entry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Secret bool   `json:"secret"`
+}
+
+// Describe lists every value bound from an environment variable, in field order
+// Secret fields and values resolved from a secret reference are secret,
+// when redact is true their non-empty values are replaced by RedactedValue
+func (c *Config) Describe(redact bool) []Entry {
+	var entries []Entry
+	describe(reflect.ValueOf(c).Elem(), func(key string, field reflect.StructField, value reflect.Value) {
+		e := Entry{
+			Key:    key,
+			Value:  formatValue(value),
+			Source: c.Source(key),
+			Secret: isSecret(value.Type()) || c.secrets[key],
+		}
+		if redact && e.Secret && e.Value != "" {
+			e.Value = RedactedValue
+		}
+		entries = append(entries, e)
+	})
+	return entries
+}
+
+// describe walks v like Bind does and calls fn for every field with an `env` tag
+func describe(v reflect.Value, fn func(key string, field reflect.StructField, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		if key := field.Tag.Get(TagEnv); key != "" {
+			fn(key, field, fv)
+			continue
+		}
+		switch {
+		case fv.Kind() == reflect.Struct:
+			describe(fv, fn)
+		case fv.Kind() == reflect.Pointer && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct:
+			describe(fv.Elem(), fn)
+		}
+	}
+}
+
+// isSecret reports whether a field type is a Secret or a list or map of them
+func isSecret(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t == secretType
+}
+
+// formatValue formats a value the way Bind parses it back
+func formatValue(v reflect.Value) string {
+	switch v.Type() {
+	case durationType:
+		return v.Interface().(fmt.Stringer).String()
+	case fileModeType:
+		return fmt.Sprintf("%#o", v.Uint())
+	case secretType:
+		return v.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, formatValue(iter.Key())+"="+formatValue(iter.Value()))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 )
 
+// Config holds every setting of the application
+// Fields are bound from environment variables by their `env` tag, see Bind
 type Config struct {
 	App           *App
 	Database      *Database
@@ -14,88 +16,185 @@ type Config struct {
 	Queue         *Queue
 	Schedule      *Schedule
 	SMTP          *SMTP
+	SES           *SES
+	S3            *S3
+	LocalStorage  *LocalStorage
+	Logging       *Logging
+	Metrics       *Metrics
+	Tracing       *Tracing
+	Health        *Health
+	Admin         *Admin
+	Auth          *Auth
+
+	sources map[string]string // where each value came from, keyed by environment variable
+	secrets map[string]bool   // values resolved from a secret reference, keyed by environment variable
+	opts    Options           // the options the config was loaded with, used to reload it
+	files   []string          // the config and env files the config was read from, watched for changes
 }
 
 // App only this struct can deliver to module
 type App struct {
-	Name        string
-	Environment string
-	Version     string
+	Name                string `env:"APP_NAME" default:"GoStarter" validate:"validateRequired"`
+	Environment         string
+	Version             string        `env:"APP_VERSION" default:"1.0.0"`
+	ConfigWatchInterval time.Duration `env:"CONFIG_WATCH_INTERVAL" default:"5s"` // how often config files are checked for changes, 0 reloads on SIGHUP only
 }
 
 type DeliveryHttp struct {
-	Prefork       bool
-	CaseSensitive bool
-	StrictRouting bool
-	BodyLimit     int
-	ServerHeader  string
+	Host            string         `env:"HTTP_HOST"` // empty listens on every interface
+	Port            int            `env:"HTTP_PORT" default:"8080" validate:"nummin=1,nummax=65535"`
+	Socket          string         `env:"HTTP_SOCKET"` // path of a Unix socket to listen on instead of host and port
+	ReadTimeout     time.Duration  `env:"HTTP_READ_TIMEOUT" default:"30s"`
+	WriteTimeout    time.Duration  `env:"HTTP_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout     time.Duration  `env:"HTTP_IDLE_TIMEOUT" default:"120s"`
+	TLSCertFile     string         `env:"HTTP_TLS_CERT_FILE"` // serves HTTPS when set together with the key file
+	TLSKeyFile      string         `env:"HTTP_TLS_KEY_FILE"`
+	TLSClientCAFile string         `env:"HTTP_TLS_CLIENT_CA_FILE"` // requires client certificates signed by this CA (mTLS)
+	Prefork         bool           `env:"HTTP_PREFORK" default:"false"`
+	CaseSensitive   bool           `env:"HTTP_CASE_SENSITIVE" default:"true"`
+	StrictRouting   bool           `env:"HTTP_STRICT_ROUTING" default:"false"`
+	BodyLimit       int            `env:"HTTP_BODY_LIMIT" default:"10485760" validate:"nummin=0"`
+	ServerHeader    string         `env:"HTTP_SERVER_HEADER" default:"GoStarter"`
+	ErrorFormat     string         `env:"HTTP_ERROR_FORMAT" default:"json"`       // json envelope or problem for RFC 7807 problem+json
+	AccessLogRoutes map[string]int `env:"HTTP_ACCESS_LOG_ROUTES"`                 // route=N logs 1 in N requests of a route, 0 none, like /ping=0
+	AccessLogStatus map[string]int `env:"HTTP_ACCESS_LOG_STATUS"`                 // class=N logs 1 in N requests of a status class, like 2xx=10
+	ShutdownTimeout time.Duration  `env:"HTTP_SHUTDOWN_TIMEOUT" default:"5s"`     // how long in-flight requests may drain on shutdown
+	FailurePolicy   string         `env:"HTTP_FAILURE_POLICY" default:"shutdown"` // shutdown or continue, when running with other services in one process
 }
 
 type DeliveryQueue struct {
-	Concurrency int
+	Concurrency     int           `env:"QUEUE_CONCURRENCY" default:"10" validate:"nummin=1"`
+	ShutdownTimeout time.Duration `env:"QUEUE_SHUTDOWN_TIMEOUT" default:"8s"`     // how long in-flight tasks may finish on shutdown
+	FailurePolicy   string        `env:"QUEUE_FAILURE_POLICY" default:"shutdown"` // shutdown or continue, when running with other services in one process
 }
 
 type Database struct {
-	User            string
-	Password        string
-	Host            string
-	Port            int
-	Database        string
-	Params          string // opsional: tambahan param seperti charset=utf8mb4
-	MaxOpenConns    int
-	MaxIdleConns    int
-	ConnMaxLifetime time.Duration
-	ConnMaxIdleTime time.Duration
+	User            string        `env:"DB_USER" default:"root" validate:"validateRequired"`
+	Password        Secret        `env:"DB_PASSWORD"`
+	Host            string        `env:"DB_HOST" default:"localhost" validate:"validateRequired"`
+	Port            int           `env:"DB_PORT" default:"3306" validate:"nummin=1,nummax=65535"`
+	Database        string        `env:"DB_NAME" default:"gostarter" validate:"validateRequired"`
+	Params          string        `env:"DB_PARAMS" default:"charset=utf8mb4&parseTime=true"` // opsional: tambahan param seperti charset=utf8mb4
+	MaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"10" validate:"nummin=0"`
+	MaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"5" validate:"nummin=0"`
+	ConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"1h"`
+	ConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME" default:"30m"`
 }
 
 type Redis struct {
-	Addr            string
-	Password        string
-	DB              int
-	PoolSize        int
-	MinIdleConns    int
-	ConnMaxLifetime time.Duration
-	PoolTimeout     time.Duration
-	ConnMaxIdleTime time.Duration
-	ReadTimeout     time.Duration
-	WriteTimeout    time.Duration
-	DialTimeout     time.Duration
+	Addr            string        `env:"REDIS_ADDR" default:"localhost:6379" validate:"validateRequired"`
+	Password        Secret        `env:"REDIS_PASSWORD"`
+	DB              int           `env:"REDIS_DB" default:"0" validate:"nummin=0,nummax=15"`
+	PoolSize        int           `env:"REDIS_POOL_SIZE" default:"10" validate:"nummin=1"`
+	MinIdleConns    int           `env:"REDIS_MIN_IDLE_CONNS" default:"5" validate:"nummin=0"`
+	ConnMaxLifetime time.Duration `env:"REDIS_CONN_MAX_LIFETIME" default:"1h"`
+	PoolTimeout     time.Duration `env:"REDIS_POOL_TIMEOUT" default:"4s"`
+	ConnMaxIdleTime time.Duration `env:"REDIS_CONN_MAX_IDLE_TIME" default:"30m"`
+	ReadTimeout     time.Duration `env:"REDIS_READ_TIMEOUT" default:"3s"`
+	WriteTimeout    time.Duration `env:"REDIS_WRITE_TIMEOUT" default:"3s"`
+	DialTimeout     time.Duration `env:"REDIS_DIAL_TIMEOUT" default:"5s"`
 }
 
 type Queue struct {
-	Concurrency int
+	Concurrency int `env:"QUEUE_WORKER_CONCURRENCY" default:"10" validate:"nummin=1"`
 }
 
 type Schedule struct {
-	Timezone string
+	Timezone        string        `env:"SCHEDULE_TIMEZONE" default:"UTC"`
+	ShutdownTimeout time.Duration `env:"SCHEDULE_SHUTDOWN_TIMEOUT" default:"5s"`     // how long the scheduler may take to stop on shutdown
+	FailurePolicy   string        `env:"SCHEDULE_FAILURE_POLICY" default:"shutdown"` // shutdown or continue, when running with other services in one process
 }
 
 type SMTP struct {
-	Host              string
-	Port              int
-	Username          string
-	Password          string
-	AuthType          string // one of => CRAM-MD5, CUSTOM, LOGIN, LOGIN-NOENC, NOAUTH, PLAIN, PLAIN-NOENC, XOAUTH2, SCRAM-SHA-1, SCRAM-SHA-1-PLUS, SCRAM-SHA-256, SCRAM-SHA-256-PLUS, SCRAM-SHA-384, SCRAM-SHA-384-PLUS, SCRAM-SHA-512, SCRAM-SHA-512-PLUS, AUTODISCOVER
-	WithTLSPortPolicy int    // one of => 0 = Mandatory, 1 = Opportunistic, 2 = no tls
+	Host              string `env:"SMTP_HOST" default:"smtp.gmail.com" validate:"validateRequired"`
+	Port              int    `env:"SMTP_PORT" default:"587" validate:"nummin=1,nummax=65535"`
+	Username          string `env:"SMTP_USERNAME"`
+	Password          Secret `env:"SMTP_PASSWORD"`
+	AuthType          string `env:"SMTP_AUTH_TYPE" default:"PLAIN"`                                     // one of => CRAM-MD5, CUSTOM, LOGIN, LOGIN-NOENC, NOAUTH, PLAIN, PLAIN-NOENC, XOAUTH2, SCRAM-SHA-1, SCRAM-SHA-1-PLUS, SCRAM-SHA-256, SCRAM-SHA-256-PLUS, SCRAM-SHA-384, SCRAM-SHA-384-PLUS, SCRAM-SHA-512, SCRAM-SHA-512-PLUS, AUTODISCOVER
+	WithTLSPortPolicy int    `env:"SMTP_WITH_TLS_PORT_POLICY" default:"0" validate:"nummin=0,nummax=2"` // one of => 0 = Mandatory, 1 = Opportunistic, 2 = no tls
 }
 
 type SES struct {
-	Region string
+	Region string `env:"SES_REGION" default:"us-west-2"`
 }
 
 type S3 struct {
-	Region               string
-	Bucket               string
-	AccessKey            string
-	SecretKey            string
-	Session              string
-	Url                  string // url for generate url, if fill this field, it will be used to generate url for file, example https://minio.example.com for usePathStyleEndpoint = true, and https://bucket.minio.example.com for usePathStyleEndpoint = false
-	UseStylePathEndpoint bool   // if true, format will be s3.amazonaws.com/bucket, if false, format will be bucket.s3.amazonaws.com
+	Region               string `env:"S3_REGION"`
+	Bucket               string `env:"S3_BUCKET"`
+	AccessKey            Secret `env:"S3_ACCESS_KEY"`
+	SecretKey            Secret `env:"S3_SECRET_KEY"`
+	Session              Secret `env:"S3_SESSION"`
+	Url                  string `env:"S3_URL"`                                     // url for generate url, if fill this field, it will be used to generate url for file, example https://minio.example.com for usePathStyleEndpoint = true, and https://bucket.minio.example.com for usePathStyleEndpoint = false
+	UseStylePathEndpoint bool   `env:"S3_USE_PATH_STYLE_ENDPOINT" default:"false"` // if true, format will be s3.amazonaws.com/bucket, if false, format will be bucket.s3.amazonaws.com
 }
 
 type LocalStorage struct {
-	BasePath              string
-	BaseURL               string
-	DefaultDirPermission  os.FileMode // default 0755
-	DefaultFilePermission os.FileMode // default 0644
+	BasePath              string      `env:"STORAGE_LOCAL_BASE_PATH" default:"storage"`
+	BaseURL               string      `env:"STORAGE_LOCAL_BASE_URL"`
+	DefaultDirPermission  os.FileMode `env:"STORAGE_LOCAL_DIR_PERMISSION" default:"0755"`  // default 0755
+	DefaultFilePermission os.FileMode `env:"STORAGE_LOCAL_FILE_PERMISSION" default:"0644"` // default 0644
+}
+
+type Logging struct {
+	Backend        string        `env:"LOG_BACKEND" default:"slog"`     // slog or zap
+	Level          string        `env:"LOG_LEVEL" default:"info"`       // debug, info, warn or error, reloaded at runtime
+	Format         string        `env:"LOG_FORMAT" default:"text"`      // json, text or console
+	Outputs        []string      `env:"LOG_OUTPUTS" default:"stdout"`   // comma separated stdout, stderr and file
+	AddSource      bool          `env:"LOG_ADD_SOURCE" default:"false"` // include the file and line of the log call
+	FilePath       string        `env:"LOG_FILE_PATH" default:"logs/app.log"`
+	FileRotation   string        `env:"LOG_FILE_ROTATION" default:"daily"`                      // daily, size or none
+	FileMaxSizeMB  int           `env:"LOG_FILE_MAX_SIZE_MB" default:"100" validate:"nummin=1"` // size rotation threshold
+	FileMaxAge     time.Duration `env:"LOG_FILE_MAX_AGE" default:"168h"`                        // rotated files older than this are removed, 0 keeps them
+	FileMaxBackups int           `env:"LOG_FILE_MAX_BACKUPS" default:"7" validate:"nummin=0"`   // rotated files kept, 0 keeps all
+	Redact         bool          `env:"LOG_REDACT" default:"true"`                              // mask sensitive fields and values
+	RedactKeys     []string      `env:"LOG_REDACT_KEYS" default:"password,passwd,secret,token,authorization,api_key,apikey,cookie,credential"`
+	RedactEmails   bool          `env:"LOG_REDACT_EMAILS" default:"true"`
+	RedactCards    bool          `env:"LOG_REDACT_CARDS" default:"true"`
+	Sampling       bool          `env:"LOG_SAMPLING" default:"false"`                            // sample repeated messages, see the three settings below
+	SampleInterval time.Duration `env:"LOG_SAMPLE_INTERVAL" default:"1s"`                        // window the first entries are counted in
+	SampleFirst    int           `env:"LOG_SAMPLE_FIRST" default:"100" validate:"nummin=0"`      // entries of a message kept per window
+	SampleEvery    int           `env:"LOG_SAMPLE_THEREAFTER" default:"100" validate:"nummin=0"` // then keep 1 in this many, 0 drops the rest
+}
+
+type Metrics struct {
+	Enabled bool   `env:"METRICS_ENABLED" default:"true"`
+	Path    string `env:"METRICS_PATH" default:"/metrics"` // served by the HTTP server, and by the sidecar listener without it
+	Addr    string `env:"METRICS_ADDR" default:":9090"`    // sidecar listener of the worker and scheduler, empty disables it
+}
+
+type Tracing struct {
+	Enabled      bool    `env:"TRACING_ENABLED" default:"false"`
+	Exporter     string  `env:"TRACING_EXPORTER" default:"stdout"`              // stdout or otlp
+	OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" default:"localhost:4318"` // host:port of the OTLP/HTTP collector
+	OTLPInsecure bool    `env:"TRACING_OTLP_INSECURE" default:"false"`          // send to the collector over plain HTTP
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" default:"1"`               // share of new traces recorded, 0 to 1
+}
+
+type Health struct {
+	Addr          string        `env:"HEALTH_ADDR" default:":9090"` // probe listener of the worker and scheduler, empty disables it, may be METRICS_ADDR
+	LivenessPath  string        `env:"HEALTH_LIVENESS_PATH" default:"/healthz"`
+	ReadinessPath string        `env:"HEALTH_READINESS_PATH" default:"/readyz"`
+	CheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"2s"` // timeout of the adapter checks
+}
+
+type Admin struct {
+	Enabled bool   `env:"ADMIN_ENABLED" default:"false"`
+	Addr    string `env:"ADMIN_ADDR" default:"127.0.0.1:9091"` // bind it to a private interface only
+	Token   Secret `env:"ADMIN_TOKEN"`                         // required when enabled, sent as Authorization: Bearer <token>
+}
+
+type Auth struct {
+	JWTAlgorithms    []string          `env:"AUTH_JWT_ALGORITHMS"`      // accepted signing algorithms like HS256 or RS256, empty disables JWT
+	JWTSecret        Secret            `env:"AUTH_JWT_SECRET"`          // HMAC key of the HS algorithms
+	JWTPublicKeyFile string            `env:"AUTH_JWT_PUBLIC_KEY_FILE"` // PEM RSA or ECDSA key of the RS and ES algorithms, for tokens without kid
+	JWTJWKSFile      string            `env:"AUTH_JWT_JWKS_FILE"`       // JSON Web Key Set, keys are picked by the kid header of the token
+	JWTIssuer        string            `env:"AUTH_JWT_ISSUER"`          // required iss claim, not checked when empty
+	JWTAudience      string            `env:"AUTH_JWT_AUDIENCE"`        // required aud claim, not checked when empty
+	JWTGroupsClaim   string            `env:"AUTH_JWT_GROUPS_CLAIM" default:"groups"`
+	JWTLeeway        time.Duration     `env:"AUTH_JWT_LEEWAY" default:"30s"` // clock skew allowed on exp and nbf
+	APIKeyStore      string            `env:"AUTH_API_KEY_STORE"`            // mysql or redis, empty disables API keys
+	APIKeyHeader     string            `env:"AUTH_API_KEY_HEADER" default:"X-API-Key"`
+	BasicUsers       map[string]Secret `env:"AUTH_BASIC_USERS"`  // username=bcrypt hash, empty disables basic auth
+	BasicGroups      map[string]string `env:"AUTH_BASIC_GROUPS"` // username=groups separated by |, like ops=admin|deploy
+	BasicRealm       string            `env:"AUTH_BASIC_REALM" default:"GoStarter"`
 }
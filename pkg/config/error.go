@@ -0,0 +1,25 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+)
+
+// Error reports every configuration problem found while loading
+// Each problem's Field is the name of the environment variable holding the value
+type Error struct {
+	Problems validation.Errors
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p.Field)
+		b.WriteString(": ")
+		b.WriteString(p.Message)
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Secret is a configuration value that must never be printed or logged
+// Formatting, JSON encoding and slog all render it as RedactedValue, use Value to read it
+type Secret string
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// Value returns the plain secret
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) redacted() string {
+	if s == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+// String returns the redacted secret
+func (s Secret) String() string {
+	return s.redacted()
+}
+
+// GoString returns the redacted secret for %#v
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.redacted())
+}
+
+// Format redacts the secret for every fmt verb
+func (s Secret) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		_, _ = fmt.Fprintf(f, "%q", s.redacted())
+		return
+	}
+	_, _ = f.Write([]byte(s.redacted()))
+}
+
+// MarshalJSON encodes the redacted secret
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.redacted())
+}
+
+// LogValue logs the redacted secret
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.redacted())
+}
+
+// SecretProvider resolves secret references of one scheme, e.g. "vault" for vault://secret/db#password
+// Any config value of the form <scheme>://<reference> is passed to the provider registered for the scheme
+type SecretProvider interface {
+	// Scheme returns the reference scheme handled by the provider, without "://"
+	Scheme() string
+	// Resolve returns the secret for a reference, ref is the part after "<scheme>://"
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// FileSecretProvider resolves file:///path/to/secret references, e.g. docker or kubernetes secrets
+// Trailing newlines of the file are removed
+type FileSecretProvider struct{}
+
+func (FileSecretProvider) Scheme() string {
+	return "file"
+}
+
+func (FileSecretProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	content, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// EnvSecretProvider resolves env://OTHER_VAR references from another variable
+// Load resolves them against every source, so OTHER_VAR may come from an env file, the config file or a flag
+type EnvSecretProvider struct {
+	// Lookup reads a variable, os.LookupEnv when nil
+	Lookup func(key string) (string, bool)
+}
+
+func (EnvSecretProvider) Scheme() string {
+	return "env"
+}
+
+func (p EnvSecretProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	lookup := p.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	value, ok := lookup(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return value, nil
+}
+
+// secretResolver resolves references with the built-in providers and the ones given in Options
+type secretResolver struct {
+	providers map[string]SecretProvider
+}
+
+// env:// references are looked up in the layers
+func newSecretResolver(l layers, providers []SecretProvider) *secretResolver {
+	env := EnvSecretProvider{Lookup: func(key string) (string, bool) {
+		value, _, ok := l.lookup(key)
+		return value, ok
+	}}
+	r := &secretResolver{providers: make(map[string]SecretProvider)}
+	for _, p := range append([]SecretProvider{FileSecretProvider{}, env}, providers...) {
+		r.providers[p.Scheme()] = p
+	}
+	return r
+}
+
+// resolve returns the secret when raw is a reference of a registered scheme, ok reports whether it was one
+func (r *secretResolver) resolve(ctx context.Context, raw string) (value string, ok bool, err error) {
+	scheme, ref, found := strings.Cut(raw, "://")
+	if !found {
+		return raw, false, nil
+	}
+	provider, registered := r.providers[scheme]
+	if !registered {
+		return raw, false, nil
+	}
+
+	value, err = provider.Resolve(ctx, ref)
+	if err != nil {
+		return "", true, fmt.Errorf("failed to resolve secret %s://%s: %w", scheme, ref, err)
+	}
+	return value, true, nil
+}
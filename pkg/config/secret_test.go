@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type staticProvider map[string]string
+
+func (p staticProvider) Scheme() string {
+	return "vault"
+}
+
+func (p staticProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	value, ok := p[ref]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", ref)
+	}
+	return value, nil
+}
+
+func TestLoadResolvesSecrets(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	secretFile := filepath.Join(dir, "db_password")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_PASSWORD", "file://"+secretFile)
+	t.Setenv("OTHER_REDIS_PASSWORD", "from-env")
+	t.Setenv("REDIS_PASSWORD", "env://OTHER_REDIS_PASSWORD")
+	t.Setenv("DB_USER", "vault://db#user")
+
+	cfg, err := Load(Options{SecretProviders: []SecretProvider{staticProvider{"db#user": "app"}}})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Database.Password.Value() != "from-file" {
+		t.Errorf("Expected password from file, got %q", cfg.Database.Password.Value())
+	}
+	if cfg.Redis.Password.Value() != "from-env" {
+		t.Errorf("Expected password from env, got %q", cfg.Redis.Password.Value())
+	}
+	if cfg.Database.User != "app" {
+		t.Errorf("Expected user from provider, got %q", cfg.Database.User)
+	}
+
+	for _, e := range cfg.Describe(true) {
+		if e.Key == "DB_USER" && (!e.Secret || e.Value != RedactedValue) {
+			t.Errorf("Expected DB_USER resolved from a reference to be redacted, got %+v", e)
+		}
+	}
+}
+
+func TestEnvSecretFromEveryLayer(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".env", []byte("SHARED_PASSWORD=from-dotenv\nREDIS_PASSWORD=env://SHARED_PASSWORD\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(Options{Flags: map[string]string{"ADMIN_TOKEN": "from-flag", "DB_PASSWORD": "env://ADMIN_TOKEN"}})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Redis.Password.Value() != "from-dotenv" {
+		t.Errorf("Expected password from .env, got %q", cfg.Redis.Password.Value())
+	}
+	if cfg.Database.Password.Value() != "from-flag" {
+		t.Errorf("Expected password from a flag, got %q", cfg.Database.Password.Value())
+	}
+}
+
+func TestSecretIsRedacted(t *testing.T) {
+	db := Database{Password: "p4ssw0rd"}
+
+	encoded, err := json.Marshal(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := []string{
+		fmt.Sprintf("%v", db),
+		fmt.Sprintf("%+v", db),
+		fmt.Sprintf("%#v", db),
+		fmt.Sprintf("%s", db.Password),
+		string(encoded),
+	}
+	for _, out := range outputs {
+		if strings.Contains(out, "p4ssw0rd") {
+			t.Errorf("Expected secret to be redacted, got %s", out)
+		}
+	}
+}
+
+func TestDescribeRedactsSecretMaps(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("AUTH_BASIC_USERS", "ops=$2a$10$hash")
+
+	cfg, err := Load(Options{})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Auth.BasicUsers["ops"].Value() != "$2a$10$hash" {
+		t.Errorf("Expected the hash to be bound, got %q", cfg.Auth.BasicUsers["ops"].Value())
+	}
+
+	for _, e := range cfg.Describe(true) {
+		if e.Key == "AUTH_BASIC_USERS" && (!e.Secret || e.Value != RedactedValue) {
+			t.Errorf("Expected AUTH_BASIC_USERS to be redacted, got %+v", e)
+		}
+	}
+	for _, e := range cfg.Describe(false) {
+		if e.Key == "AUTH_BASIC_USERS" && e.Value != "ops=$2a$10$hash" {
+			t.Errorf("Expected the unredacted value to round-trip, got %q", e.Value)
+		}
+	}
+}
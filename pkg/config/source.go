@@ -0,0 +1,230 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the sources a value can come from, see Source
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+	SourceUnset   = "unset"
+)
+
+// layer is one configuration source, values are keyed by environment variable name
+type layer struct {
+	name   string
+	values map[string]string
+}
+
+// layers resolves a key against every source, the last layer has the highest precedence
+type layers []layer
+
+// An empty value counts as unset, except on the command line where -set KEY= clears the value
+func (l layers) lookup(key string) (value, source string, ok bool) {
+	for i := len(l) - 1; i >= 0; i-- {
+		if value, ok := l[i].values[key]; ok && (value != "" || l[i].name == SourceFlag) {
+			return value, l[i].name, true
+		}
+	}
+	return "", "", false
+}
+
+// processEnv returns the process environment as a layer
+func processEnv() layer {
+	values := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			values[k] = v
+		}
+	}
+	return layer{name: SourceEnv, values: values}
+}
+
+// readEnvFile reads a dotenv file without exporting it to the process environment
+// A missing file returns an empty layer
+func readEnvFile(path string) (layer, error) {
+	values, err := godotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return layer{name: path}, nil
+	}
+	if err != nil {
+		return layer{}, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return layer{name: path, values: values}, nil
+}
+
+// readConfigFile reads a YAML, JSON or TOML file chosen by its extension
+// Keys are environment variable names like DB_HOST, or sections named after the Config fields
+// like `database: {host: x}` or `delivery_http: {port: 8080}`. Lists are joined with commas
+// and the maps of map settings become key=value pairs. Any other key is reported as an *Error
+func readConfigFile(path string) (layer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return layer{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	data := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &data)
+	case ".json":
+		err = json.Unmarshal(content, &data)
+	case ".toml":
+		err = toml.Unmarshal(content, &data)
+	default:
+		return layer{}, fmt.Errorf("unsupported config file format %q, use .yaml, .yml, .json or .toml", ext)
+	}
+	if err != nil {
+		return layer{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	f := flattener{index: configSettings(), values: make(map[string]string)}
+	f.flatten(nil, data)
+	for i := range f.problems {
+		f.problems[i].Message += " in " + path
+	}
+	if f.problems.HasErrors() {
+		return layer{}, &Error{Problems: f.problems}
+	}
+	return layer{name: path, values: f.values}, nil
+}
+
+// settingIndex knows every setting of Config by environment variable name and by section and field name
+type settingIndex struct {
+	maps    map[string]bool   // whether the setting holds a map, keyed by environment variable name
+	aliases map[string]string // environment variable name keyed by the normalized section and field name
+}
+
+// configSettings indexes the `env` tags of the Config sections
+var configSettings = sync.OnceValue(func() settingIndex {
+	index := settingIndex{maps: make(map[string]bool), aliases: make(map[string]string)}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		section := t.Field(i)
+		if !section.IsExported() || section.Type.Kind() != reflect.Pointer || section.Type.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		fields := section.Type.Elem()
+		for j := 0; j < fields.NumField(); j++ {
+			field := fields.Field(j)
+			key := field.Tag.Get(TagEnv)
+			if key == "" {
+				continue
+			}
+			index.maps[key] = field.Type.Kind() == reflect.Map
+			index.aliases[normalizeKey(section.Name+field.Name)] = key
+		}
+	}
+	return index
+})
+
+// resolve returns the environment variable name of a config file key path
+func (s settingIndex) resolve(path []string) (string, bool) {
+	key := strings.ToUpper(strings.Join(path, "_"))
+	if _, ok := s.maps[key]; ok {
+		return key, true
+	}
+	key, ok := s.aliases[normalizeKey(strings.Join(path, ""))]
+	return key, ok
+}
+
+// normalizeKey makes delivery_http.tls_cert_file and DeliveryHttp.TLSCertFile compare equal
+func normalizeKey(s string) string {
+	return strings.ToUpper(strings.NewReplacer("_", "", "-", "", ".", "").Replace(s))
+}
+
+// flattener turns the nested values of a config file into settings keyed by environment variable name
+type flattener struct {
+	index    settingIndex
+	values   map[string]string
+	problems validation.Errors
+}
+
+func (f *flattener) flatten(path []string, value any) {
+	if v, ok := value.(map[string]any); ok {
+		if key, known := f.index.resolve(path); known && f.index.maps[key] {
+			f.values[key] = pairs(v)
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f.flatten(append(slices.Clone(path), k), v[k])
+		}
+		return
+	}
+
+	key, known := f.index.resolve(path)
+	if !known {
+		f.problems = append(f.problems, validation.Error{Field: strings.Join(path, "."), Message: "is not a known setting"})
+		return
+	}
+	if items, ok := value.([]any); ok {
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			list = append(list, scalar(item))
+		}
+		f.values[key] = strings.Join(list, ",")
+		return
+	}
+	f.values[key] = scalar(value)
+}
+
+// pairs encodes a map as the key=value list of a map setting
+func pairs(m map[string]any) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]string, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, k+"="+scalar(m[k]))
+	}
+	return strings.Join(list, ",")
+}
+
+func scalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ParseFlag parses a KEY=VALUE command line value into Options.Flags
+func ParseFlag(flags map[string]string, kv string) error {
+	key, value, ok := strings.Cut(kv, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("invalid config flag %q, expected KEY=VALUE", kv)
+	}
+	flags[strings.TrimSpace(key)] = value
+	return nil
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/auth"
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+)
+
+// SMTPAuthTypes lists the accepted values of SMTP_AUTH_TYPE
+var SMTPAuthTypes = []string{
+	"CRAM-MD5", "CUSTOM", "LOGIN", "LOGIN-NOENC", "NOAUTH", "PLAIN", "PLAIN-NOENC", "XOAUTH2",
+	"SCRAM-SHA-1", "SCRAM-SHA-1-PLUS", "SCRAM-SHA-256", "SCRAM-SHA-256-PLUS",
+	"SCRAM-SHA-384", "SCRAM-SHA-384-PLUS", "SCRAM-SHA-512", "SCRAM-SHA-512-PLUS", "AUTODISCOVER",
+}
+
+// FailurePolicies lists the accepted values of the *_FAILURE_POLICY variables
+var FailurePolicies = []string{"shutdown", "continue"}
+
+// ErrorFormats lists the accepted values of HTTP_ERROR_FORMAT
+var ErrorFormats = []string{"json", "problem"}
+
+// LogBackends, LogLevels, LogFormats, LogOutputs and LogRotations list the accepted values of the LOG_* variables
+var (
+	LogBackends  = []string{"slog", "zap"}
+	LogLevels    = []string{"debug", "info", "warn", "error"}
+	LogFormats   = []string{"json", "text", "console"}
+	LogOutputs   = []string{"stdout", "stderr", "file"}
+	LogRotations = []string{"daily", "size", "none"}
+)
+
+// TracingExporters lists the accepted values of TRACING_EXPORTER
+var TracingExporters = []string{"stdout", "otlp"}
+
+// APIKeyStores lists the accepted values of AUTH_API_KEY_STORE, AUTH_JWT_ALGORITHMS accepts auth.JWTAlgorithms
+var APIKeyStores = []string{"mysql", "redis"}
+
+// StatusClasses lists the accepted keys of HTTP_ACCESS_LOG_STATUS
+var StatusClasses = []string{"1xx", "2xx", "3xx", "4xx", "5xx"}
+
+// check validates one config value that its struct tags can't express, identified by its environment variable
+type check struct {
+	env   string
+	value any
+	rule  validation.Rule
+}
+
+// Validate checks the rules the `validate` tags can't express and returns an *Error listing every problem
+// Bind already checks the `validate` tags
+func (c *Config) Validate() error {
+	checks := []check{
+		{env: "DB_CONN_MAX_LIFETIME", value: c.Database.ConnMaxLifetime, rule: nonNegativeDuration},
+		{env: "DB_CONN_MAX_IDLE_TIME", value: c.Database.ConnMaxIdleTime, rule: nonNegativeDuration},
+
+		{env: "HTTP_READ_TIMEOUT", value: c.DeliveryHttp.ReadTimeout, rule: nonNegativeDuration},
+		{env: "HTTP_WRITE_TIMEOUT", value: c.DeliveryHttp.WriteTimeout, rule: nonNegativeDuration},
+		{env: "HTTP_IDLE_TIMEOUT", value: c.DeliveryHttp.IdleTimeout, rule: nonNegativeDuration},
+		{env: "HTTP_TLS_CERT_FILE", value: c.DeliveryHttp.TLSCertFile, rule: requiredWith("HTTP_TLS_KEY_FILE", c.DeliveryHttp.TLSKeyFile)},
+		{env: "HTTP_TLS_KEY_FILE", value: c.DeliveryHttp.TLSKeyFile, rule: requiredWith("HTTP_TLS_CERT_FILE", c.DeliveryHttp.TLSCertFile)},
+		{env: "HTTP_TLS_CERT_FILE", value: c.DeliveryHttp.TLSCertFile, rule: requiredWith("HTTP_TLS_CLIENT_CA_FILE", c.DeliveryHttp.TLSClientCAFile)},
+		{env: "HTTP_SOCKET", value: c.DeliveryHttp.Socket, rule: excludedWith("HTTP_PREFORK", c.DeliveryHttp.Prefork)},
+		{env: "HTTP_ERROR_FORMAT", value: c.DeliveryHttp.ErrorFormat, rule: oneOf(ErrorFormats)},
+		{env: "HTTP_SHUTDOWN_TIMEOUT", value: c.DeliveryHttp.ShutdownTimeout, rule: nonNegativeDuration},
+		{env: "HTTP_FAILURE_POLICY", value: c.DeliveryHttp.FailurePolicy, rule: oneOf(FailurePolicies)},
+
+		{env: "QUEUE_SHUTDOWN_TIMEOUT", value: c.DeliveryQueue.ShutdownTimeout, rule: nonNegativeDuration},
+		{env: "QUEUE_FAILURE_POLICY", value: c.DeliveryQueue.FailurePolicy, rule: oneOf(FailurePolicies)},
+
+		{env: "REDIS_CONN_MAX_LIFETIME", value: c.Redis.ConnMaxLifetime, rule: nonNegativeDuration},
+		{env: "REDIS_POOL_TIMEOUT", value: c.Redis.PoolTimeout, rule: nonNegativeDuration},
+		{env: "REDIS_CONN_MAX_IDLE_TIME", value: c.Redis.ConnMaxIdleTime, rule: nonNegativeDuration},
+		{env: "REDIS_READ_TIMEOUT", value: c.Redis.ReadTimeout, rule: nonNegativeDuration},
+		{env: "REDIS_WRITE_TIMEOUT", value: c.Redis.WriteTimeout, rule: nonNegativeDuration},
+		{env: "REDIS_DIAL_TIMEOUT", value: c.Redis.DialTimeout, rule: nonNegativeDuration},
+
+		{env: "SCHEDULE_TIMEZONE", value: c.Schedule.Timezone, rule: timezone},
+		{env: "SCHEDULE_SHUTDOWN_TIMEOUT", value: c.Schedule.ShutdownTimeout, rule: nonNegativeDuration},
+		{env: "SCHEDULE_FAILURE_POLICY", value: c.Schedule.FailurePolicy, rule: oneOf(FailurePolicies)},
+
+		{env: "SMTP_AUTH_TYPE", value: c.SMTP.AuthType, rule: oneOf(SMTPAuthTypes)},
+
+		{env: "LOG_BACKEND", value: c.Logging.Backend, rule: oneOf(LogBackends)},
+		{env: "LOG_LEVEL", value: c.Logging.Level, rule: oneOf(LogLevels)},
+		{env: "LOG_FORMAT", value: c.Logging.Format, rule: oneOf(LogFormats)},
+		{env: "LOG_FILE_ROTATION", value: c.Logging.FileRotation, rule: oneOf(LogRotations)},
+		{env: "LOG_FILE_MAX_AGE", value: c.Logging.FileMaxAge, rule: nonNegativeDuration},
+		{env: "LOG_SAMPLE_INTERVAL", value: c.Logging.SampleInterval, rule: nonNegativeDuration},
+
+		{env: "METRICS_PATH", value: c.Metrics.Path, rule: absolutePath},
+
+		{env: "TRACING_EXPORTER", value: c.Tracing.Exporter, rule: oneOf(TracingExporters)},
+		{env: "TRACING_SAMPLE_RATIO", value: c.Tracing.SampleRatio, rule: ratio},
+
+		{env: "HEALTH_LIVENESS_PATH", value: c.Health.LivenessPath, rule: absolutePath},
+		{env: "HEALTH_READINESS_PATH", value: c.Health.ReadinessPath, rule: absolutePath},
+		{env: "HEALTH_CHECK_TIMEOUT", value: c.Health.CheckTimeout, rule: nonNegativeDuration},
+		// the HTTP server serves the probes and the metrics on one listener, and so does the sidecar when METRICS_ADDR is HEALTH_ADDR
+		{env: "HEALTH_READINESS_PATH", value: c.Health.ReadinessPath, rule: differentFrom("HEALTH_LIVENESS_PATH", c.Health.LivenessPath, true)},
+		{env: "METRICS_PATH", value: c.Metrics.Path, rule: differentFrom("HEALTH_LIVENESS_PATH", c.Health.LivenessPath, c.Metrics.Enabled)},
+		{env: "METRICS_PATH", value: c.Metrics.Path, rule: differentFrom("HEALTH_READINESS_PATH", c.Health.ReadinessPath, c.Metrics.Enabled)},
+
+		{env: "ADMIN_TOKEN", value: c.Admin.Token.Value(), rule: requiredIf("ADMIN_ENABLED", c.Admin.Enabled)},
+		{env: "ADMIN_ADDR", value: c.Admin.Addr, rule: requiredIf("ADMIN_ENABLED", c.Admin.Enabled)},
+
+		{env: "AUTH_JWT_LEEWAY", value: c.Auth.JWTLeeway, rule: nonNegativeDuration},
+	}
+	for class, n := range c.DeliveryHttp.AccessLogStatus {
+		checks = append(checks, check{env: "HTTP_ACCESS_LOG_STATUS", value: class, rule: oneOf(StatusClasses)})
+		checks = append(checks, check{env: "HTTP_ACCESS_LOG_STATUS", value: n, rule: nonNegative})
+	}
+	for _, n := range c.DeliveryHttp.AccessLogRoutes {
+		checks = append(checks, check{env: "HTTP_ACCESS_LOG_ROUTES", value: n, rule: nonNegative})
+	}
+	var hmacAlgs, publicKeyAlgs []string
+	for _, alg := range c.Auth.JWTAlgorithms {
+		checks = append(checks, check{env: "AUTH_JWT_ALGORITHMS", value: alg, rule: oneOf(auth.JWTAlgorithms)})
+		if strings.HasPrefix(alg, "HS") {
+			hmacAlgs = append(hmacAlgs, alg)
+		} else {
+			publicKeyAlgs = append(publicKeyAlgs, alg)
+		}
+	}
+	checks = append(checks,
+		check{env: "AUTH_JWT_SECRET", value: c.Auth.JWTSecret.Value(), rule: requiredWith("an HS algorithm in AUTH_JWT_ALGORITHMS", strings.Join(hmacAlgs, ","))},
+		check{env: "AUTH_JWT_PUBLIC_KEY_FILE", value: c.Auth.JWTPublicKeyFile, rule: excludedWith("AUTH_JWT_SECRET, both verify tokens without kid", c.Auth.JWTSecret.Value() != "")},
+		// either key file is enough
+		check{env: "AUTH_JWT_PUBLIC_KEY_FILE", value: c.Auth.JWTPublicKeyFile + c.Auth.JWTJWKSFile, rule: requiredWith("an RS or ES algorithm in AUTH_JWT_ALGORITHMS without AUTH_JWT_JWKS_FILE", strings.Join(publicKeyAlgs, ","))},
+	)
+	basicUsers := slices.Sorted(maps.Keys(c.Auth.BasicUsers))
+	for user := range c.Auth.BasicGroups {
+		checks = append(checks, check{env: "AUTH_BASIC_GROUPS", value: user, rule: oneOf(basicUsers)})
+	}
+	if c.Auth.APIKeyStore != "" {
+		checks = append(checks, check{env: "AUTH_API_KEY_STORE", value: c.Auth.APIKeyStore, rule: oneOf(APIKeyStores)})
+	}
+	for _, output := range c.Logging.Outputs {
+		checks = append(checks, check{env: "LOG_OUTPUTS", value: output, rule: oneOf(LogOutputs)})
+	}
+
+	var errs validation.Errors
+	for _, c := range checks {
+		if err := c.rule(c.env, c.value); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+	if errs.HasErrors() {
+		return &Error{Problems: errs}
+	}
+	return nil
+}
+
+var nonNegativeDuration = validation.Custom(func(field string, value any) *validation.Error {
+	if d, ok := value.(time.Duration); ok && d < 0 {
+		return &validation.Error{Field: field, Message: "must not be negative"}
+	}
+	return nil
+})
+
+var nonNegative = validation.Custom(func(field string, value any) *validation.Error {
+	if n, ok := value.(int); ok && n < 0 {
+		return &validation.Error{Field: field, Message: fmt.Sprintf("must not be negative, got %d", n)}
+	}
+	return nil
+})
+
+var absolutePath = validation.Custom(func(field string, value any) *validation.Error {
+	if s, _ := value.(string); !strings.HasPrefix(s, "/") {
+		return &validation.Error{Field: field, Message: fmt.Sprintf("must start with /, got %q", s)}
+	}
+	return nil
+})
+
+var ratio = validation.Custom(func(field string, value any) *validation.Error {
+	if f, ok := value.(float64); ok && (f < 0 || f > 1) {
+		return &validation.Error{Field: field, Message: fmt.Sprintf("must be between 0 and 1, got %v", f)}
+	}
+	return nil
+})
+
+var timezone = validation.Custom(func(field string, value any) *validation.Error {
+	name, _ := value.(string)
+	if _, err := time.LoadLocation(name); err != nil {
+		return &validation.Error{Field: field, Message: fmt.Sprintf("is not a valid timezone, got %q", name)}
+	}
+	return nil
+})
+
+func oneOf(values []string) validation.Rule {
+	return validation.Custom(func(field string, value any) *validation.Error {
+		s, _ := value.(string)
+		if !slices.Contains(values, s) {
+			return &validation.Error{Field: field, Message: fmt.Sprintf(validation.ErrorMessageOneOf, strings.Join(values, ", ")) + fmt.Sprintf(", got %q", s)}
+		}
+		return nil
+	})
+}
+
+// requiredWith requires the value when the other variable is set
+func requiredWith(other string, otherValue string) validation.Rule {
+	return validation.Custom(func(field string, value any) *validation.Error {
+		if s, _ := value.(string); s == "" && otherValue != "" {
+			return &validation.Error{Field: field, Message: fmt.Sprintf("is required when %s is set", other)}
+		}
+		return nil
+	})
+}
+
+// requiredIf requires the value when the other option is enabled
+func requiredIf(other string, otherEnabled bool) validation.Rule {
+	return validation.Custom(func(field string, value any) *validation.Error {
+		if s, _ := value.(string); s == "" && otherEnabled {
+			return &validation.Error{Field: field, Message: fmt.Sprintf("is required when %s is enabled", other)}
+		}
+		return nil
+	})
+}
+
+// excludedWith rejects the value when the other option is enabled
+func excludedWith(other string, otherEnabled bool) validation.Rule {
+	return validation.Custom(func(field string, value any) *validation.Error {
+		if s, _ := value.(string); s != "" && otherEnabled {
+			return &validation.Error{Field: field, Message: fmt.Sprintf("can't be used together with %s", other)}
+		}
+		return nil
+	})
+}
+
+// differentFrom rejects the value when it equals the other variable and both are served on one listener
+func differentFrom(other string, otherValue string, sameListener bool) validation.Rule {
+	return validation.Custom(func(field string, value any) *validation.Error {
+		if s, _ := value.(string); s == otherValue && sameListener {
+			return &validation.Error{Field: field, Message: fmt.Sprintf("must differ from %s, both are served on one listener", other)}
+		}
+		return nil
+	})
+}
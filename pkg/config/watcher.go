@@ -0,0 +1,186 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+)
+
+// Watcher holds the live configuration and reloads it on SIGHUP or when one of its files changes
+// A reloaded snapshot is validated before it replaces the current one, an invalid snapshot is
+// logged and discarded. Subscribers are notified of the sections that changed
+type Watcher struct {
+	current  atomic.Pointer[Config]
+	reloadMu sync.Mutex // serializes reloads, so subscribers see every change in order
+
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]subscriber
+	modTimes    map[string]time.Time
+}
+
+// Listener is called after a section changed, with the previous and the new snapshot
+type Listener func(old, new *Config)
+
+type subscriber struct {
+	section string
+	fn      Listener
+}
+
+// NewWatcher creates a watcher holding cfg, reloads use the options cfg was loaded with
+func NewWatcher(cfg *Config) *Watcher {
+	w := &Watcher{
+		subscribers: make(map[int]subscriber),
+		modTimes:    modTimes(cfg.files),
+	}
+	w.current.Store(cfg)
+	return w
+}
+
+// Current returns the current snapshot, it is never modified, hold it only for one unit of work
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Subscribe calls fn when the given section changes, section is a Config field name like "Queue"
+// An empty section subscribes to every change. It returns a function removing the subscription
+func (w *Watcher) Subscribe(section string, fn Listener) (unsubscribe func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextID
+	w.nextID++
+	w.subscribers[id] = subscriber{section: section, fn: fn}
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subscribers, id)
+	}
+}
+
+// Subscribed reports whether something subscribed to the given section, and so applies its changes at runtime
+func (w *Watcher) Subscribed(section string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, s := range w.subscribers {
+		if s.section == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload loads a new snapshot from the same sources, validates it and swaps it in
+// It returns the load error and keeps the current snapshot when the new one is invalid
+func (w *Watcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	old := w.Current()
+	cfg, err := Load(old.opts)
+	if err != nil {
+		return err
+	}
+
+	w.current.Store(cfg)
+	changed := ChangedSections(old, cfg)
+
+	w.mu.Lock()
+	w.modTimes = modTimes(cfg.files)
+	var listeners []Listener
+	for _, s := range w.subscribers {
+		if s.section == "" && len(changed) > 0 || changed[s.section] {
+			listeners = append(listeners, s.fn)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(old, cfg)
+	}
+	return nil
+}
+
+// Watch reloads on SIGHUP and, when interval is positive, when a config or env file changes
+// It blocks until ctx is done
+func (w *Watcher) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			w.reload(ctx, "signal")
+		case <-tick:
+			if w.filesChanged() {
+				w.reload(ctx, "file change")
+			}
+		}
+	}
+}
+
+func (w *Watcher) reload(ctx context.Context, reason string) {
+	if err := w.Reload(); err != nil {
+		logging.Error(ctx, "Config reload failed, keeping the current config",
+			logging.NewField("reason", reason),
+			logging.NewField("error", err),
+		)
+		// do not retry the same broken files on every tick
+		w.mu.Lock()
+		w.modTimes = modTimes(w.Current().files)
+		w.mu.Unlock()
+		return
+	}
+	logging.Info(ctx, "Config reloaded", logging.NewField("reason", reason))
+}
+
+func (w *Watcher) filesChanged() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !reflect.DeepEqual(w.modTimes, modTimes(w.Current().files))
+}
+
+// modTimes returns the modification time of every existing file
+func modTimes(files []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(files))
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			times[f] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// ChangedSections returns the names of the top-level sections that differ between two snapshots
+func ChangedSections(old, new *Config) map[string]bool {
+	changed := make(map[string]bool)
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	for i := 0; i < ov.NumField(); i++ {
+		field := ov.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			changed[field.Name] = true
+		}
+	}
+	return changed
+}
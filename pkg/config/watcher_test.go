@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/shared/constant"
+)
+
+func TestWatcherReload(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	envFile := filepath.Join(dir, ".env.staging")
+	write := func(content string) {
+		if err := os.WriteFile(envFile, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("QUEUE_WORKER_CONCURRENCY=5\n")
+	cfg, err := Load(Options{Environment: constant.EnvironmentStaging})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	w := NewWatcher(cfg)
+	var queueCalls, redisCalls int
+	w.Subscribe("Queue", func(old, new *Config) {
+		queueCalls++
+		if old.Queue.Concurrency != 5 || new.Queue.Concurrency != 8 {
+			t.Errorf("Expected concurrency to change from 5 to 8, got %d to %d", old.Queue.Concurrency, new.Queue.Concurrency)
+		}
+	})
+	w.Subscribe("Redis", func(old, new *Config) { redisCalls++ })
+	if !w.Subscribed("Queue") || w.Subscribed("Database") {
+		t.Errorf("Expected only Queue and Redis to be subscribed, got Queue %t, Database %t", w.Subscribed("Queue"), w.Subscribed("Database"))
+	}
+
+	write("QUEUE_WORKER_CONCURRENCY=8\n")
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if queueCalls != 1 || redisCalls != 0 {
+		t.Errorf("Expected only the Queue subscriber to be called, got queue %d, redis %d", queueCalls, redisCalls)
+	}
+	if w.Current().Queue.Concurrency != 8 {
+		t.Errorf("Expected the current config to be swapped, got concurrency %d", w.Current().Queue.Concurrency)
+	}
+
+	// an invalid snapshot is rejected and the current one is kept
+	write("QUEUE_WORKER_CONCURRENCY=0\n")
+	if err := w.Reload(); err == nil {
+		t.Fatal("Expected reload of an invalid config to fail")
+	}
+	if w.Current().Queue.Concurrency != 8 || queueCalls != 1 {
+		t.Errorf("Expected the previous config to be kept, got concurrency %d", w.Current().Queue.Concurrency)
+	}
+}
+
+func TestWatcherConcurrentReload(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// every load resolves a new password, so every reload changes the Database section
+	t.Setenv("DB_PASSWORD", "counter://db")
+	cfg, err := Load(Options{SecretProviders: []SecretProvider{&counterProvider{}}})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	w := NewWatcher(cfg)
+	last := cfg
+	var outOfOrder int
+	w.Subscribe("Database", func(old, new *Config) {
+		if old != last {
+			outOfOrder++
+		}
+		last = new
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.Reload(); err != nil {
+				t.Errorf("Failed to reload config: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if outOfOrder != 0 || last != w.Current() {
+		t.Errorf("Expected every reload to start from the snapshot of the previous one, got %d out of order", outOfOrder)
+	}
+}
+
+type counterProvider struct {
+	n atomic.Int64
+}
+
+func (p *counterProvider) Scheme() string { return "counter" }
+
+func (p *counterProvider) Resolve(_ context.Context, ref string) (string, error) {
+	return fmt.Sprintf("%s-%d", ref, p.n.Add(1)), nil
+}
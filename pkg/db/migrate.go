@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// MigrationTable stores the name of every applied migration
+const MigrationTable = "schema_migrations"
+
+// Migration is a single SQL migration file
+type Migration struct {
+	Name    string
+	Applied bool
+}
+
+// Migrations lists every *.sql file in dir sorted by name, with whether it has been applied
+func Migrations(ctx context.Context, db *sql.DB, dir fs.FS) ([]Migration, error) {
+	if err := ensureMigrationTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := fs.Glob(dir, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	migrations := make([]Migration, 0, len(names))
+	for _, name := range names {
+		migrations = append(migrations, Migration{Name: name, Applied: applied[name]})
+	}
+	return migrations, nil
+}
+
+// Migrate applies every pending migration in dir in name order and returns the names it applied
+// Each file runs in its own transaction, statements are separated by ";" at the end of a line
+// MySQL commits DDL statements implicitly, so a failing file may be partially applied
+func Migrate(ctx context.Context, db *sql.DB, dir fs.FS) ([]string, error) {
+	migrations, err := Migrations(ctx, db, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for _, m := range migrations {
+		if m.Applied {
+			continue
+		}
+
+		content, err := fs.ReadFile(dir, m.Name)
+		if err != nil {
+			return done, fmt.Errorf("failed to read migration %s: %w", m.Name, err)
+		}
+
+		if err := applyMigration(ctx, db, m.Name, string(content)); err != nil {
+			return done, err
+		}
+		done = append(done, m.Name)
+	}
+	return done, nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, name, content string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", name, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, stmt := range splitStatements(content) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO "+MigrationTable+" (name) VALUES (?)", name); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", name, err)
+	}
+	return nil
+}
+
+func ensureMigrationTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+MigrationTable+` (
+		name VARCHAR(255) NOT NULL PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", MigrationTable, err)
+	}
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM "+MigrationTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = true
+	}
+	return applied, rows.Err()
+}
+
+// splitStatements splits a SQL file on ";" at the end of a line and drops empty statements
+func splitStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			if stmt := strings.TrimSpace(current.String()); stmt != ";" {
+				statements = append(statements, strings.TrimSuffix(stmt, ";"))
+			}
+			current.Reset()
+		}
+	}
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
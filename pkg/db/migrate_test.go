@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSplitStatements(t *testing.T) {
+	content := `-- create users
+CREATE TABLE users (
+	id INT PRIMARY KEY,
+	note VARCHAR(10) DEFAULT 'a;b'
+);
+
+;
+INSERT INTO users (id) VALUES (1);
+  -- indented comment
+INSERT INTO users (id)
+VALUES (2)
+`
+	want := []string{
+		"CREATE TABLE users (\n\tid INT PRIMARY KEY,\n\tnote VARCHAR(10) DEFAULT 'a;b'\n)",
+		"INSERT INTO users (id) VALUES (1)",
+		"INSERT INTO users (id)\nVALUES (2)",
+	}
+	if got := splitStatements(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if got := splitStatements("-- only a comment\n\n"); len(got) != 0 {
+		t.Errorf("Expected no statements, got %q", got)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	dir := fstest.MapFS{
+		"001_users.sql":  {Data: []byte("CREATE TABLE users (id INT);\n")},
+		"002_orders.sql": {Data: []byte("CREATE TABLE orders (id INT);\nCREATE INDEX idx ON orders (id);\n")},
+		"003_broken.sql": {Data: []byte("CREATE TABLE;\n")},
+		"README.md":      {Data: []byte("not a migration")},
+	}
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT name FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("001_users.sql"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE orders \(id INT\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE INDEX idx ON orders \(id\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs("002_orders.sql").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE").WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+
+	applied, err := Migrate(context.Background(), db, dir)
+	if err == nil {
+		t.Error("Expected the broken migration to fail")
+	}
+	if !reflect.DeepEqual(applied, []string{"002_orders.sql"}) {
+		t.Errorf("Expected only 002_orders.sql to be applied, got %v", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
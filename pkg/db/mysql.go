@@ -4,22 +4,25 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/XSAM/otelsql"
 	"github.com/fatkulnurk/gostarter/pkg/config"
 	_ "github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
 )
 
 // NewMySQL membuat koneksi MySQL baru berdasarkan konfigurasi
 func NewMySQL(cfg *config.Database) (*sql.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?%s",
 		cfg.User,
-		cfg.Password,
+		cfg.Password.Value(),
 		cfg.Host,
 		cfg.Port,
 		cfg.Database,
 		cfg.Params,
 	)
 
-	db, err := sql.Open("mysql", dsn)
+	// spans are dropped until a tracer provider is installed, see pkg/tracing
+	db, err := otelsql.Open("mysql", dsn, otelsql.WithAttributes(semconv.DBSystemNameMySQL))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open mysql connection: %w", err)
 	}
@@ -8,6 +8,7 @@ import (
 
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -32,7 +33,7 @@ func NewRedis(cfg *config.Redis) (*redis.Client, error) {
 
 	rdb := redis.NewClient(&redis.Options{
 		Addr:            cfg.Addr,
-		Password:        cfg.Password,
+		Password:        cfg.Password.Value(),
 		DB:              cfg.DB,
 		PoolSize:        cfg.PoolSize,
 		MinIdleConns:    cfg.MinIdleConns,
@@ -44,6 +45,11 @@ func NewRedis(cfg *config.Redis) (*redis.Client, error) {
 		DialTimeout:     cfg.DialTimeout,
 	})
 
+	// spans are dropped until a tracer provider is installed, see pkg/tracing
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		return nil, fmt.Errorf("failed to instrument redis: %w", err)
+	}
+
 	// Test connection with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
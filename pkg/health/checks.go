@@ -0,0 +1,54 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fatkulnurk/gostarter/pkg/storage"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/wneessen/go-mail"
+)
+
+// SQL pings the database
+func SQL(db *sql.DB) Func {
+	return db.PingContext
+}
+
+// Redis pings the redis server
+func Redis(client redis.UniversalClient) Func {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// Asynq lists the queues of the asynq broker, it takes no context so it is abandoned on timeout
+func Asynq(inspector *asynq.Inspector) Func {
+	return func(ctx context.Context) error {
+		_, err := inspector.Queues()
+		return err
+	}
+}
+
+// Storage looks the path up in the storage, the path does not need to exist
+func Storage(s storage.Storage, path string) Func {
+	return func(ctx context.Context) error {
+		_, err := s.Exists(ctx, path)
+		return err
+	}
+}
+
+// SMTP connects to the SMTP server and sends a NOOP
+func SMTP(client *mail.Client) Func {
+	return func(ctx context.Context) error {
+		conn, err := client.DialToSMTPClientWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = client.CloseWithSMTPClient(conn)
+		}()
+		return conn.Noop()
+	}
+}
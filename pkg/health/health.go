@@ -0,0 +1,198 @@
+// Package health runs the dependency checks behind the liveness and readiness probes
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status of a check or of the whole service
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDown     Status = "down"
+	StatusDegraded Status = "degraded" // only non-critical checks are down
+)
+
+// DefaultTimeout bounds a check registered without a timeout
+const DefaultTimeout = 2 * time.Second
+
+// Func checks one dependency, it returns an error when the dependency can't be used
+type Func func(ctx context.Context) error
+
+// Check is a named dependency check
+type Check struct {
+	Name     string
+	Func     Func
+	Timeout  time.Duration // DefaultTimeout when zero
+	Critical bool          // a failing critical check makes the service not ready
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	Critical   bool   `json:"critical"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report is the outcome of every check
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// Registry holds the checks registered by the adapters and the modules
+type Registry struct {
+	mu     sync.RWMutex
+	checks []Check
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+var std = NewRegistry()
+
+// Default returns the process wide registry served by /healthz and /readyz
+func Default() *Registry {
+	return std
+}
+
+// Register adds the checks, a check replaces the one registered before under the same name
+func (r *Registry) Register(checks ...Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, check := range checks {
+		if check.Timeout <= 0 {
+			check.Timeout = DefaultTimeout
+		}
+		replaced := false
+		for i := range r.checks {
+			if r.checks[i].Name == check.Name {
+				r.checks[i] = check
+				replaced = true
+			}
+		}
+		if !replaced {
+			r.checks = append(r.checks, check)
+		}
+	}
+}
+
+// Checks returns the registered checks in registration order
+func (r *Registry) Checks() []Check {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Check(nil), r.checks...)
+}
+
+// Run runs every check concurrently, each within its timeout
+func (r *Registry) Run(ctx context.Context) Report {
+	checks := r.Checks()
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status == StatusUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusDown
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+// run runs one check, a check ignoring its context is abandoned once the timeout passes
+func run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- check.Func(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{Name: check.Name, Status: StatusUp, Critical: check.Critical, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler answers 200 as long as the process serves requests, it runs no check
+// so a dependency outage doesn't get the process restarted
+func (r *Registry) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusUp})
+	})
+}
+
+// ReadinessHandler runs every check and answers 503 when a critical one is down
+// It only reports the status of each check, serve it on public listeners
+func (r *Registry) ReadinessHandler() http.Handler {
+	return r.readinessHandler(false)
+}
+
+// ReadinessDetailHandler is ReadinessHandler with the error of every failing check,
+// which may name hosts or credentials, serve it on internal listeners only
+func (r *Registry) ReadinessDetailHandler() http.Handler {
+	return r.readinessHandler(true)
+}
+
+func (r *Registry) readinessHandler(detail bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Run(req.Context())
+		status := http.StatusOK
+		if report.Status == StatusDown {
+			status = http.StatusServiceUnavailable
+		}
+		if !detail {
+			for i := range report.Checks {
+				report.Checks[i].Error = ""
+			}
+		}
+		writeReport(w, status, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
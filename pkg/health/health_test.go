@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func up(ctx context.Context) error { return nil }
+
+func down(ctx context.Context) error { return errors.New("connection refused") }
+
+func hang(ctx context.Context) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		want   Status
+	}{
+		{"every check up", []Check{{Name: "mysql", Func: up, Critical: true}}, StatusUp},
+		{"non-critical down", []Check{{Name: "mysql", Func: up, Critical: true}, {Name: "smtp", Func: down}}, StatusDegraded},
+		{"critical down", []Check{{Name: "mysql", Func: down, Critical: true}, {Name: "smtp", Func: down}}, StatusDown},
+		{"critical timed out", []Check{{Name: "asynq", Func: hang, Timeout: 10 * time.Millisecond, Critical: true}}, StatusDown},
+		{"no check", nil, StatusUp},
+	}
+	for _, tt := range tests {
+		r := NewRegistry()
+		r.Register(tt.checks...)
+		if report := r.Run(context.Background()); report.Status != tt.want {
+			t.Errorf("%s: expected %s, got %s (%+v)", tt.name, tt.want, report.Status, report.Checks)
+		}
+	}
+}
+
+func TestRegisterReplacesByName(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{Name: "redis", Func: down, Critical: true})
+	r.Register(Check{Name: "redis", Func: up, Critical: true})
+
+	checks := r.Checks()
+	if len(checks) != 1 || checks[0].Timeout != DefaultTimeout {
+		t.Fatalf("Expected one check with the default timeout, got %+v", checks)
+	}
+	if report := r.Run(context.Background()); report.Status != StatusUp {
+		t.Errorf("Expected the replacing check to run, got %s", report.Status)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{Name: "mysql", Func: down, Critical: true})
+
+	rec := httptest.NewRecorder()
+	r.LivenessHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected liveness to ignore the checks, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", rec.Code)
+	}
+
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Checks) != 1 || report.Checks[0].Status != StatusDown || report.Checks[0].Error != "" {
+		t.Errorf("Expected the failing check without its error, got %+v", report.Checks)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ReadinessDetailHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	report = Report{}
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || len(report.Checks) != 1 || report.Checks[0].Error != "connection refused" {
+		t.Errorf("Expected the failing check in the detail, got %d %+v", rec.Code, report.Checks)
+	}
+}
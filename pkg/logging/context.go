@@ -0,0 +1,51 @@
+package logging
+
+import "context"
+
+type (
+	fieldsKey struct{}
+	loggerKey struct{}
+)
+
+// NewContext returns a context carrying the logger, the global log functions called with it use that logger
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context, the global logger when there is none
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+			return logger
+		}
+	}
+	return Default()
+}
+
+// WithFields returns a context carrying the fields in addition to the ones already attached,
+// every log call made with the context includes them
+func WithFields(ctx context.Context, fields ...Field) context.Context {
+	existing := ContextFields(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// ContextFields returns the fields attached to the context
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]Field)
+	return fields
+}
+
+// withContextFields prepends the context fields to the fields of a log call
+func withContextFields(ctx context.Context, fields []Field) []Field {
+	attached := ContextFields(ctx)
+	if len(attached) == 0 {
+		return fields
+	}
+	return append(attached[:len(attached):len(attached)], fields...)
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var levelNames = map[LogLevel]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l LogLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel parses debug, info, warn (or warning) and error
+func ParseLevel(s string) (LogLevel, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "warning" {
+		name = "warn"
+	}
+	for level, n := range levelNames {
+		if n == name {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// LevelVar is a minimum level that can be changed while loggers built with it are in use
+type LevelVar struct {
+	level atomic.Int64
+}
+
+// NewLevelVar creates a level variable set to level
+func NewLevelVar(level LogLevel) *LevelVar {
+	v := &LevelVar{}
+	v.Set(level)
+	return v
+}
+
+func (v *LevelVar) Get() LogLevel {
+	return LogLevel(v.level.Load())
+}
+
+func (v *LevelVar) Set(level LogLevel) {
+	v.level.Store(int64(level))
+}
+
+// ServeHTTP reports the level on GET and changes it on PUT or POST with {"level": "debug"} or ?level=debug
+func (v *LevelVar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		name := r.URL.Query().Get("level")
+		if name == "" {
+			var body struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeLevel(w, http.StatusBadRequest, map[string]string{"error": "invalid body: " + err.Error()})
+				return
+			}
+			name = body.Level
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			writeLevel(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		previous := v.Get()
+		v.Set(level)
+		Info(r.Context(), "Log level changed", NewField("from", previous.String()), NewField("to", level.String()))
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevel(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	writeLevel(w, http.StatusOK, map[string]string{"level": v.Get().String()})
+}
+
+func writeLevel(w http.ResponseWriter, status int, body map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// slogLeveler adapts a LevelVar to slog
+type slogLeveler struct{ v *LevelVar }
+
+func (s slogLeveler) Level() slog.Level {
+	return toSlogLevel(s.v.Get())
+}
+
+// zapEnabler adapts a LevelVar to zap
+type zapEnabler struct{ v *LevelVar }
+
+func (z zapEnabler) Enabled(level zapcore.Level) bool {
+	return level >= toZapLevel(z.v.Get())
+}
+
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func toZapLevel(level LogLevel) zapcore.Level {
+	switch level {
+	case LevelDebug:
+		return zapcore.DebugLevel
+	case LevelWarn:
+		return zapcore.WarnLevel
+	case LevelError:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
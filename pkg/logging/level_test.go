@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevelVarServeHTTP(t *testing.T) {
+	v := NewLevelVar(LevelInfo)
+	ctx := NewContext(context.Background(), NewSlogLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		level                LogLevel
+	}{
+		{"GET", "/", "", 200, LevelInfo},
+		{"PUT", "/", `{"level": "debug"}`, 200, LevelDebug},
+		{"POST", "/?level=warning", "", 200, LevelWarn},
+		{"PUT", "/", `{"level": "loud"}`, 400, LevelWarn},
+		{"DELETE", "/", "", 405, LevelWarn},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		v.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status || v.Get() != tt.level {
+			t.Errorf("%s %s %s: expected %d and level %s, got %d and %s", tt.method, tt.target, tt.body, tt.status, tt.level, rec.Code, v.Get())
+		}
+	}
+}
+
+func TestNewZapJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	level := NewLevelVar(LevelWarn)
+	logger, closer, err := New(Options{
+		Backend: BackendZap,
+		Level:   level,
+		Format:  FormatJSON,
+		Outputs: []string{OutputFile},
+		File:    RotateOptions{Path: path, Rotation: RotateNone},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build logger: %v", err)
+	}
+
+	ctx := context.Background()
+	logger.Info(ctx, "hidden")
+	level.Set(LevelDebug)
+	logger.Debug(ctx, "shown", NewField("attempt", 1))
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the entry after the level change, got %q", content)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil || entry["msg"] != "shown" || entry["attempt"] != float64(1) {
+		t.Errorf("Expected a JSON entry for shown, got %s", lines[0])
+	}
+}
@@ -2,14 +2,17 @@ package logging
 
 import (
 	"context"
-	"sync"
+	"sync/atomic"
 )
 
+// Logger writes structured logs, every implementation also emits the fields attached to ctx with WithFields
 type Logger interface {
 	Debug(ctx context.Context, msg string, fields ...Field)
 	Info(ctx context.Context, msg string, fields ...Field)
 	Warning(ctx context.Context, msg string, fields ...Field)
 	Error(ctx context.Context, msg string, fields ...Field)
+	// With returns a child logger that adds the fields to every entry
+	With(fields ...Field) Logger
 }
 
 // LogLevel represents the log level
@@ -33,32 +36,67 @@ func NewField(key string, value any) Field {
 }
 
 var (
-	once sync.Once
-	l    Logger
+	global atomic.Pointer[holder]
+	level  = NewLevelVar(LevelInfo)
 )
 
+// holder wraps the global logger, atomic.Pointer needs a concrete type
+type holder struct{ logger Logger }
+
+// Level returns the process wide minimum level used by loggers built with New
+func Level() *LevelVar {
+	return level
+}
+
+// InitLogging installs the global logger used by the package level functions, nil installs a no-op logger
+// Until it is called the global logger discards every entry
 func InitLogging(logger Logger) {
-	once.Do(func() {
-		l = logger
-	})
+	if logger == nil {
+		logger = NewNopLogger()
+	}
+	global.Store(&holder{logger: logger})
+}
+
+// Default returns the global logger
+func Default() Logger {
+	if h := global.Load(); h != nil {
+		return h.logger
+	}
+	return nop
+}
+
+// Override installs the logger until the returned function restores the previous one
+// It is meant for tests: defer logging.Override(rec)() or t.Cleanup(logging.Override(rec)),
+// tests overriding the global logger must not run in parallel
+func Override(logger Logger) (restore func()) {
+	previous := global.Load()
+	InitLogging(logger)
+	return func() {
+		global.Store(previous)
+	}
+}
+
+// With returns a child of the global logger that adds the fields to every entry
+func With(fields ...Field) Logger {
+	return Default().With(fields...)
 }
 
 // Debug logs a message at the debug level
 func Debug(ctx context.Context, msg string, fields ...Field) {
-	l.Debug(ctx, msg, fields...)
+	FromContext(ctx).Debug(ctx, msg, fields...)
 }
 
 // Info logs a message at the info level
 func Info(ctx context.Context, msg string, fields ...Field) {
-	l.Info(ctx, msg, fields...)
+	FromContext(ctx).Info(ctx, msg, fields...)
 }
 
 // Warning logs a message at the warning level
 func Warning(ctx context.Context, msg string, fields ...Field) {
-	l.Warning(ctx, msg, fields...)
+	FromContext(ctx).Warning(ctx, msg, fields...)
 }
 
 // Error logs a message at the error level
 func Error(ctx context.Context, msg string, fields ...Field) {
-	l.Error(ctx, msg, fields...)
+	FromContext(ctx).Error(ctx, msg, fields...)
 }
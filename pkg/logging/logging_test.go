@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlogContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	ctx := WithFields(context.Background(), NewField("request_id", "req-1"))
+	ctx = WithFields(ctx, NewField("user_id", 7))
+	logger.With(NewField("module", "example")).Info(ctx, "hello", NewField("attempt", 1))
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON entry, got %s", buf.String())
+	}
+	for key, want := range map[string]any{"request_id": "req-1", "user_id": float64(7), "module": "example", "attempt": float64(1)} {
+		if entry[key] != want {
+			t.Errorf("Expected %s=%v, got %v", key, want, entry[key])
+		}
+	}
+}
+
+func TestZapContextFields(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := NewZapLogger(zap.New(core))
+
+	ctx := WithFields(context.Background(), NewField("request_id", "req-1"))
+	logger.Debug(ctx, "skipped")
+	logger.With(NewField("module", "example")).Warning(ctx, "hello")
+
+	if logs.Len() != 1 {
+		t.Fatalf("Expected only the warning to be logged, got %d entries", logs.Len())
+	}
+	fields := logs.All()[0].ContextMap()
+	if fields["request_id"] != "req-1" || fields["module"] != "example" {
+		t.Errorf("Expected request_id and module fields, got %v", fields)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := NewContext(context.Background(), NewZapLogger(zap.New(core)).With(NewField("task_id", "t-1")))
+
+	// the global functions use the logger carried by the context
+	Info(ctx, "processing")
+
+	if logs.Len() != 1 || logs.All()[0].ContextMap()["task_id"] != "t-1" {
+		t.Errorf("Expected one entry from the context logger, got %v", logs.All())
+	}
+}
+
+func TestOverride(t *testing.T) {
+	restoreOuter := Override(nil)
+	defer restoreOuter()
+
+	// the package level functions are safe before any logger is installed
+	Info(context.Background(), "discarded")
+	if Default() != nop {
+		t.Fatalf("Expected the no-op logger, got %T", Default())
+	}
+
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	restore := Override(logger)
+	Info(context.Background(), "overridden")
+	if buf.Len() == 0 {
+		t.Error("Expected the override to receive the entry")
+	}
+
+	restore()
+	if Default() != nop {
+		t.Errorf("Expected the previous logger to be restored, got %T", Default())
+	}
+}
@@ -0,0 +1,161 @@
+// Package logtest records log entries in memory so tests can assert on them
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+)
+
+// Entry is one recorded log call
+type Entry struct {
+	Level   logging.LogLevel
+	Message string
+	Fields  []logging.Field // the context fields, the With fields and the call fields, in that order
+}
+
+// Field returns the value of the last field with the key
+func (e Entry) Field(key string) (any, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+func (e Entry) String() string {
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = fmt.Sprintf("%s=%v", f.Key, f.Value)
+	}
+	return fmt.Sprintf("%s %q %s", e.Level, e.Message, strings.Join(fields, " "))
+}
+
+// Recorder is a logging.Logger keeping every entry in memory, children created by With share its entries
+type Recorder struct {
+	store  *store
+	fields []logging.Field
+}
+
+type store struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{store: &store{}}
+}
+
+// Capture installs a new recorder as the global logger and restores the previous one when the test ends
+func Capture(t testing.TB) *Recorder {
+	t.Helper()
+	r := NewRecorder()
+	t.Cleanup(logging.Override(r))
+	return r
+}
+
+func (r *Recorder) Debug(ctx context.Context, msg string, fields ...logging.Field) {
+	r.record(ctx, logging.LevelDebug, msg, fields)
+}
+
+func (r *Recorder) Info(ctx context.Context, msg string, fields ...logging.Field) {
+	r.record(ctx, logging.LevelInfo, msg, fields)
+}
+
+func (r *Recorder) Warning(ctx context.Context, msg string, fields ...logging.Field) {
+	r.record(ctx, logging.LevelWarn, msg, fields)
+}
+
+func (r *Recorder) Error(ctx context.Context, msg string, fields ...logging.Field) {
+	r.record(ctx, logging.LevelError, msg, fields)
+}
+
+func (r *Recorder) With(fields ...logging.Field) logging.Logger {
+	return &Recorder{store: r.store, fields: append(r.fields[:len(r.fields):len(r.fields)], fields...)}
+}
+
+func (r *Recorder) record(ctx context.Context, level logging.LogLevel, msg string, fields []logging.Field) {
+	var all []logging.Field
+	all = append(all, logging.ContextFields(ctx)...)
+	all = append(all, r.fields...)
+	all = append(all, fields...)
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.entries = append(r.store.entries, Entry{Level: level, Message: msg, Fields: all})
+}
+
+// Entries returns a copy of every recorded entry
+func (r *Recorder) Entries() []Entry {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return append([]Entry(nil), r.store.entries...)
+}
+
+// Filter returns the entries with the message, an empty message matches every entry
+func (r *Recorder) Filter(msg string) []Entry {
+	var matched []Entry
+	for _, e := range r.Entries() {
+		if msg == "" || e.Message == msg {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// Reset forgets every recorded entry
+func (r *Recorder) Reset() {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.entries = nil
+}
+
+// ExpectMessage fails the test unless an entry with the level and message was recorded, it returns the first one
+func (r *Recorder) ExpectMessage(t testing.TB, level logging.LogLevel, msg string) Entry {
+	t.Helper()
+	for _, e := range r.Filter(msg) {
+		if e.Level == level {
+			return e
+		}
+	}
+	t.Errorf("Expected a %s entry %q, got %s", level, msg, r.dump())
+	return Entry{}
+}
+
+// ExpectField fails the test unless an entry with the message has the field set to value
+func (r *Recorder) ExpectField(t testing.TB, msg, key string, value any) {
+	t.Helper()
+	for _, e := range r.Filter(msg) {
+		if v, ok := e.Field(key); ok && reflect.DeepEqual(v, value) {
+			return
+		}
+	}
+	t.Errorf("Expected an entry %q with %s=%v, got %s", msg, key, value, r.dump())
+}
+
+// ExpectNoMessage fails the test when an entry with the message was recorded
+func (r *Recorder) ExpectNoMessage(t testing.TB, msg string) {
+	t.Helper()
+	if entries := r.Filter(msg); len(entries) > 0 {
+		t.Errorf("Expected no entry %q, got %d", msg, len(entries))
+	}
+}
+
+func (r *Recorder) dump() string {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return "no entries"
+	}
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.String()
+	}
+	return "\n\t" + strings.Join(lines, "\n\t")
+}
@@ -0,0 +1,65 @@
+package logtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+)
+
+func TestCapture(t *testing.T) {
+	previous := logging.Default()
+
+	t.Run("records global calls", func(t *testing.T) {
+		rec := Capture(t)
+		ctx := logging.WithFields(context.Background(), logging.NewField("request_id", "req-1"))
+
+		logging.Info(ctx, "Incoming request", logging.NewField("status", 200))
+		logging.With(logging.NewField("worker", 1)).Warning(ctx, "Worker job failed")
+
+		rec.ExpectMessage(t, logging.LevelInfo, "Incoming request")
+		rec.ExpectField(t, "Incoming request", "request_id", "req-1")
+		rec.ExpectField(t, "Worker job failed", "worker", 1)
+		rec.ExpectNoMessage(t, "Worker job succeeded")
+		if len(rec.Entries()) != 2 {
+			t.Errorf("Expected 2 entries, got %d", len(rec.Entries()))
+		}
+	})
+
+	if logging.Default() != previous {
+		t.Error("Expected the previous logger to be restored")
+	}
+}
+
+func TestExpectFailures(t *testing.T) {
+	rec := NewRecorder()
+	rec.Error(context.Background(), "boom", logging.NewField("code", 1))
+
+	tests := []struct {
+		name   string
+		expect func(testing.TB)
+	}{
+		{"wrong level", func(tb testing.TB) { rec.ExpectMessage(tb, logging.LevelInfo, "boom") }},
+		{"wrong field value", func(tb testing.TB) { rec.ExpectField(tb, "boom", "code", 2) }},
+		{"unexpected message", func(tb testing.TB) { rec.ExpectNoMessage(tb, "boom") }},
+	}
+	for _, tt := range tests {
+		ft := &fakeT{TB: t}
+		tt.expect(ft)
+		if !ft.failed {
+			t.Errorf("%s: expected the assertion to fail", tt.name)
+		}
+	}
+}
+
+// fakeT records failures instead of failing the test
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(string, ...any) {
+	f.failed = true
+}
@@ -0,0 +1,18 @@
+package logging
+
+import "context"
+
+var nop Logger = nopLogger{}
+
+// NewNopLogger returns a logger that discards every entry
+func NewNopLogger() Logger {
+	return nop
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(context.Context, string, ...Field)   {}
+func (nopLogger) Info(context.Context, string, ...Field)    {}
+func (nopLogger) Warning(context.Context, string, ...Field) {}
+func (nopLogger) Error(context.Context, string, ...Field)   {}
+func (n nopLogger) With(...Field) Logger                    { return n }
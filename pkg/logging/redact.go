@@ -0,0 +1,219 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+)
+
+// Redacted replaces a sensitive value in a log entry
+const Redacted = "[REDACTED]"
+
+// DefaultRedactKeys are the key patterns redacted when RedactOptions.Keys is empty
+var DefaultRedactKeys = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey", "cookie", "credential"}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// 13 to 19 digits, optionally grouped by spaces or dashes, confirmed with validation.Luhn
+	cardPattern = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+)
+
+// RedactOptions selects what a Redactor masks
+type RedactOptions struct {
+	Keys        []string // field keys containing one of these, case insensitive, are masked entirely
+	Emails      bool     // mask email addresses inside values and messages
+	CardNumbers bool     // mask card numbers passing the Luhn check inside values and messages
+}
+
+// Redactor masks sensitive fields and values of log entries
+type Redactor struct {
+	keys        []string
+	emails      bool
+	cardNumbers bool
+}
+
+// NewRedactor creates a redactor, DefaultRedactKeys are used when opts.Keys is empty
+func NewRedactor(opts RedactOptions) *Redactor {
+	keys := opts.Keys
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+	r := &Redactor{emails: opts.Emails, cardNumbers: opts.CardNumbers}
+	for _, k := range keys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			r.keys = append(r.keys, k)
+		}
+	}
+	return r
+}
+
+// SensitiveKey reports whether a field with this key is masked entirely
+func (r *Redactor) SensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks the emails and card numbers inside s
+func (r *Redactor) String(s string) string {
+	if r.emails {
+		s = emailPattern.ReplaceAllString(s, Redacted)
+	}
+	if r.cardNumbers {
+		s = cardPattern.ReplaceAllStringFunc(s, func(match string) string {
+			digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+			if validation.Luhn(digits) {
+				return Redacted
+			}
+			return match
+		})
+	}
+	return s
+}
+
+// Field masks a field by its key, or the sensitive parts of its value
+func (r *Redactor) Field(f Field) Field {
+	if r.SensitiveKey(f.Key) {
+		return Field{Key: f.Key, Value: Redacted}
+	}
+	return Field{Key: f.Key, Value: r.value(f.Value)}
+}
+
+// Fields masks every field, the given slice is not modified
+func (r *Redactor) Fields(fields []Field) []Field {
+	if len(fields) == 0 {
+		return fields
+	}
+	redacted := make([]Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = r.Field(f)
+	}
+	return redacted
+}
+
+// value masks the sensitive parts of a field value, errors and fmt.Stringer values become strings
+// Structs, slices, arrays, maps and pointers are walked through their JSON form, so fields of nested values
+// are masked by their JSON key
+func (r *Redactor) value(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return r.String(v)
+	case []byte:
+		return r.String(string(v))
+	case error:
+		return r.String(v.Error())
+	case time.Time, time.Duration, json.Number:
+		// Stringers that never hold personal data, keep them typed for the encoder
+		return v
+	case slog.LogValuer:
+		resolved := v.LogValue().Resolve()
+		if resolved.Kind() == slog.KindGroup {
+			return v
+		}
+		return r.value(resolved.Any())
+	case fmt.Stringer:
+		return r.String(v.String())
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, val := range v {
+			if r.SensitiveKey(k) {
+				out[k] = Redacted
+			} else {
+				out[k] = r.String(val)
+			}
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[k] = r.Field(Field{Key: k, Value: val}).Value
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = r.value(val)
+		}
+		return out
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Pointer, reflect.Interface:
+		return r.structured(v)
+	default:
+		return v
+	}
+}
+
+// structured masks a nested value through its JSON form, so json tags and MarshalJSON apply
+func (r *Redactor) structured(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return r.String(fmt.Sprintf("%+v", v))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return r.String(string(data))
+	}
+	return r.value(decoded)
+}
+
+// Redact wraps a logger so the message, the fields and the context fields of every entry are redacted
+func Redact(logger Logger, r *Redactor) Logger {
+	return &redactLogger{logger: logger, redactor: r}
+}
+
+type redactLogger struct {
+	logger   Logger
+	redactor *Redactor
+}
+
+func (r *redactLogger) Debug(ctx context.Context, msg string, fields ...Field) {
+	ctx, msg, fields = r.redact(ctx, msg, fields)
+	r.logger.Debug(ctx, msg, fields...)
+}
+
+func (r *redactLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	ctx, msg, fields = r.redact(ctx, msg, fields)
+	r.logger.Info(ctx, msg, fields...)
+}
+
+func (r *redactLogger) Warning(ctx context.Context, msg string, fields ...Field) {
+	ctx, msg, fields = r.redact(ctx, msg, fields)
+	r.logger.Warning(ctx, msg, fields...)
+}
+
+func (r *redactLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	ctx, msg, fields = r.redact(ctx, msg, fields)
+	r.logger.Error(ctx, msg, fields...)
+}
+
+func (r *redactLogger) With(fields ...Field) Logger {
+	return &redactLogger{logger: r.logger.With(r.redactor.Fields(fields)...), redactor: r.redactor}
+}
+
+// redact merges the context fields into the entry so they are redacted too, the wrapped logger
+// receives a context without fields to not emit them twice
+func (r *redactLogger) redact(ctx context.Context, msg string, fields []Field) (context.Context, string, []Field) {
+	if attached := ContextFields(ctx); len(attached) > 0 {
+		fields = withContextFields(ctx, fields)
+		ctx = context.WithValue(ctx, fieldsKey{}, []Field(nil))
+	}
+	return ctx, r.redactor.String(msg), r.redactor.Fields(fields)
+}
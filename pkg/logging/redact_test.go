@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactorString(t *testing.T) {
+	r := NewRedactor(RedactOptions{Emails: true, CardNumbers: true})
+
+	tests := []struct {
+		in, want string
+	}{
+		{"send to jane.doe@example.com failed", "send to [REDACTED] failed"},
+		{"card 4111 1111 1111 1111 declined", "card [REDACTED] declined"},
+		{"card 4111-1111-1111-1111", "card [REDACTED]"},
+		// fails the Luhn check, like an order number
+		{"order 4111111111111112", "order 4111111111111112"},
+		{"retry 3 of 5", "retry 3 of 5"},
+	}
+	for _, tt := range tests {
+		if got := r.String(tt.in); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestRedactLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := Redact(NewZapLogger(zap.New(core)), NewRedactor(RedactOptions{Emails: true}))
+
+	ctx := WithFields(context.Background(), NewField("Authorization", "Bearer abc"))
+	logger.With(NewField("api_token", "t-1")).Error(ctx, "mail to jane@example.com failed",
+		NewField("user_password", "hunter2"),
+		NewField("error", errors.New("550 jane@example.com unknown")),
+		NewField("headers", map[string]string{"Cookie": "session=1", "Accept": "json"}),
+		NewField("attempt", 2),
+	)
+
+	if logs.Len() != 1 {
+		t.Fatalf("Expected one entry, got %d", logs.Len())
+	}
+	entry := logs.All()[0]
+	if entry.Message != "mail to [REDACTED] failed" {
+		t.Errorf("Expected the email in the message to be redacted, got %q", entry.Message)
+	}
+
+	fields := entry.ContextMap()
+	for _, key := range []string{"Authorization", "api_token", "user_password"} {
+		if fields[key] != Redacted {
+			t.Errorf("Expected %s to be redacted, got %v", key, fields[key])
+		}
+	}
+	if fields["error"] != "550 [REDACTED] unknown" {
+		t.Errorf("Expected the email in the error to be redacted, got %v", fields["error"])
+	}
+	if headers, _ := fields["headers"].(map[string]string); headers["Cookie"] != Redacted || headers["Accept"] != "json" {
+		t.Errorf("Expected only the cookie header to be redacted, got %v", fields["headers"])
+	}
+	if fields["attempt"] != int64(2) {
+		t.Errorf("Expected other fields to be kept, got %v", fields["attempt"])
+	}
+}
+
+type address struct {
+	Street string `json:"street"`
+}
+
+type customer struct {
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Password  string    `json:"password"`
+	Addresses []address `json:"addresses"`
+	Internal  string    `json:"-"`
+}
+
+type cardNumber string
+
+func (c cardNumber) String() string { return "card " + string(c) }
+
+func TestRedactorNestedValues(t *testing.T) {
+	r := NewRedactor(RedactOptions{Emails: true, CardNumbers: true})
+
+	c := &customer{
+		Name:      "Jane",
+		Email:     "jane@example.com",
+		Password:  "hunter2",
+		Addresses: []address{{Street: "mail jane@example.com"}},
+		Internal:  "jane@example.com",
+	}
+	got, ok := r.Field(NewField("customer", c)).Value.(map[string]any)
+	if !ok {
+		t.Fatalf("Expected the struct to become a map, got %T", r.Field(NewField("customer", c)).Value)
+	}
+	if got["name"] != "Jane" || got["email"] != Redacted || got["password"] != Redacted {
+		t.Errorf("Expected the email and the password to be redacted, got %v", got)
+	}
+	if _, ok := got["Internal"]; ok {
+		t.Errorf("Expected fields without a JSON name to be left out, got %v", got)
+	}
+	addresses, _ := got["addresses"].([]any)
+	if len(addresses) != 1 || addresses[0].(map[string]any)["street"] != "mail [REDACTED]" {
+		t.Errorf("Expected the email in the nested slice to be redacted, got %v", got["addresses"])
+	}
+
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{"stringer", cardNumber("4111 1111 1111 1111"), "card [REDACTED]"},
+		{"error without a match", errors.New("timeout"), "timeout"},
+		{"error", errors.New("550 jane@example.com"), "550 [REDACTED]"},
+		{"slice", []string{"jane@example.com", "ok"}, []any{Redacted, "ok"}},
+		{"nested map", map[string]any{"user": map[string]any{"token": "t-1"}}, map[string]any{"user": map[string]any{"token": Redacted}}},
+		{"nil pointer", (*customer)(nil), nil},
+		{"number", 42, 42},
+	}
+	for _, tt := range tests {
+		if got := r.Field(NewField("value", tt.in)).Value; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %v for the %s, got %#v", tt.want, tt.name, got)
+		}
+	}
+}
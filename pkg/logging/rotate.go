@@ -0,0 +1,199 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RotateDaily = "daily" // start a new file on the first write of a new day
+	RotateSize  = "size"  // start a new file when the current one would exceed MaxSize
+	RotateNone  = "none"
+)
+
+// backupTimeFormat names rotated files, it sorts in chronological order
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateOptions configures a RotatingFile
+type RotateOptions struct {
+	Path       string
+	Rotation   string        // RotateDaily, RotateSize or RotateNone
+	MaxSize    int64         // bytes, used by RotateSize
+	MaxAge     time.Duration // rotated files older than this are removed, 0 keeps them
+	MaxBackups int           // rotated files kept, 0 keeps all
+}
+
+// RotatingFile is a log file that is renamed to <name>-<time><ext> when it rotates
+// Rotated files beyond the retention are removed after every rotation
+type RotatingFile struct {
+	opts RotateOptions
+	now  func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File // nil after a failed rotation, the next write opens it again
+	closed bool
+	size   int64
+	opened time.Time // when the current file was started, drives daily rotation
+}
+
+// OpenRotatingFile opens or creates the file, creating its directory when needed
+func OpenRotatingFile(opts RotateOptions) (*RotatingFile, error) {
+	f := &RotatingFile{opts: opts, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.opts.Path), 0o755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	file, err := os.OpenFile(f.opts.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.opened = f.now()
+	if f.size > 0 {
+		// an existing file belongs to the day it was last written
+		f.opened = info.ModTime()
+	}
+	return nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	} else if f.shouldRotate(int64(len(p))) {
+		// a failed rotation that kept a file still writes the entry, and reports the failure
+		if rotateErr = f.rotate(); f.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+func (f *RotatingFile) shouldRotate(next int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	switch f.opts.Rotation {
+	case RotateDaily:
+		y1, m1, d1 := f.opened.Date()
+		y2, m2, d2 := f.now().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	case RotateSize:
+		return f.opts.MaxSize > 0 && f.size+next > f.opts.MaxSize
+	default:
+		return false
+	}
+}
+
+// rotate renames the current file, starts a new one and applies the retention
+// When the rename fails the current file is opened again and the rotation is retried on the next write
+func (f *RotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(f.opts.Path)
+	base := strings.TrimSuffix(f.opts.Path, ext)
+	backup := fmt.Sprintf("%s-%s%s", base, f.now().Format(backupTimeFormat), ext)
+	if err := os.Rename(f.opts.Path, backup); err != nil {
+		return errors.Join(fmt.Errorf("rotate log file: %w", err), f.open())
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// prune removes rotated files beyond MaxBackups or older than MaxAge, errors are ignored
+func (f *RotatingFile) prune() {
+	backups := f.Backups()
+	// newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, backup := range backups {
+		expired := false
+		if f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups {
+			expired = true
+		}
+		if f.opts.MaxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && f.now().Sub(info.ModTime()) > f.opts.MaxAge {
+				expired = true
+			}
+		}
+		if expired {
+			_ = os.Remove(backup)
+		}
+	}
+}
+
+// Backups lists the rotated files of the log file, oldest first
+func (f *RotatingFile) Backups() []string {
+	ext := filepath.Ext(f.opts.Path)
+	base := strings.TrimSuffix(f.opts.Path, ext)
+	matches, _ := filepath.Glob(base + "-*" + ext)
+
+	var backups []string
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, base+"-"), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	sort.Strings(backups)
+	return backups
+}
+
+// Sync flushes the current file to disk
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
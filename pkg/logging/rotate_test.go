@@ -0,0 +1,172 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	f, err := OpenRotatingFile(RotateOptions{Path: path, Rotation: RotateSize, MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer f.Close()
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	f.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write([]byte("12345678\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// every write after the first rotates, only the two newest backups are kept
+	if backups := f.Backups(); len(backups) != 2 {
+		t.Errorf("Expected 2 backups, got %v", backups)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "12345678\n" {
+		t.Errorf("Expected the current file to hold the last write, got %q", content)
+	}
+}
+
+func TestRotatingFileDaily(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := OpenRotatingFile(RotateOptions{Path: path, Rotation: RotateDaily})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer f.Close()
+
+	day := time.Date(2026, 10, 17, 23, 59, 0, 0, time.Local)
+	f.now = func() time.Time { return day }
+	f.opened = day
+
+	_, _ = f.Write([]byte("first\n"))
+	_, _ = f.Write([]byte("same day\n"))
+	if backups := f.Backups(); len(backups) != 0 {
+		t.Fatalf("Expected no rotation within a day, got %v", backups)
+	}
+
+	day = day.Add(2 * time.Minute)
+	_, _ = f.Write([]byte("next day\n"))
+	backups := f.Backups()
+	if len(backups) != 1 {
+		t.Fatalf("Expected one rotation on a new day, got %v", backups)
+	}
+	old, _ := os.ReadFile(backups[0])
+	if string(old) != "first\nsame day\n" {
+		t.Errorf("Expected the backup to hold the previous day, got %q", old)
+	}
+}
+
+func TestRotatingFileMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	stale := filepath.Join(dir, "app-2026-01-01T00-00-00.000.log")
+	unrelated := filepath.Join(dir, "app-notes.log")
+	for _, name := range []string{stale, unrelated} {
+		if err := os.WriteFile(name, []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	_ = os.Chtimes(stale, old, old)
+
+	f, err := OpenRotatingFile(RotateOptions{Path: path, Rotation: RotateSize, MaxSize: 1, MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer f.Close()
+	_, _ = f.Write([]byte("a\n"))
+	_, _ = f.Write([]byte("b\n"))
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("Expected the expired backup to be removed")
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Error("Expected files that are not backups to be kept")
+	}
+	if backups := f.Backups(); len(backups) != 1 {
+		t.Errorf("Expected the fresh backup to be kept, got %v", backups)
+	}
+}
+
+func TestRotatingFileRenameFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := OpenRotatingFile(RotateOptions{Path: path, Rotation: RotateSize, MaxSize: 4})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer f.Close()
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	// a non-empty directory at the backup name makes the rename fail
+	backup := filepath.Join(filepath.Dir(path), "app-"+now.Format(backupTimeFormat)+".log")
+	if err := os.MkdirAll(filepath.Join(backup, "taken"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = f.Write([]byte("abc\n"))
+	if _, err := f.Write([]byte("def\n")); err == nil {
+		t.Error("Expected the failed rotation to be reported")
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "abc\ndef\n" {
+		t.Errorf("Expected the entry to be written to the original file, got %q", content)
+	}
+
+	// the rotation is retried on the next write
+	now = now.Add(time.Second)
+	if _, err := f.Write([]byte("ghi\n")); err != nil {
+		t.Fatalf("Expected the retried rotation to succeed, got %v", err)
+	}
+	rotated, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "app-"+now.Format(backupTimeFormat)+".log"))
+	if string(rotated) != "abc\ndef\n" {
+		t.Errorf("Expected the retried rotation to back up the original file, got %q", rotated)
+	}
+}
+
+func TestRotatingFileReopenFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	path := filepath.Join(dir, "app.log")
+	f, err := OpenRotatingFile(RotateOptions{Path: path, Rotation: RotateSize, MaxSize: 4})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer f.Close()
+	_, _ = f.Write([]byte("abc\n"))
+
+	// a file in place of the log directory makes both the rename and the reopen fail
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := f.Write([]byte("def\n")); err == nil {
+			t.Error("Expected the write to fail while the file can't be opened")
+		}
+	}
+
+	// every write retries to open the file
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("ghi\n")); err != nil {
+		t.Fatalf("Expected the file to be opened again, got %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "ghi\n" {
+		t.Errorf("Expected the new file to hold the last write, got %q", content)
+	}
+}
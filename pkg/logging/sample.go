@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SampleOptions configures a Sampler: in every Interval the First entries of a key are kept,
+// after that only every Thereafter-th entry is kept
+type SampleOptions struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int // 0 drops every entry after the first ones
+}
+
+// Sampler decides which entries of a hot log path are kept, entries are grouped by key
+type Sampler struct {
+	opts SampleOptions
+	now  func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+
+	dropped atomic.Uint64
+}
+
+// NewSampler creates a sampler, an interval of zero or less defaults to a second
+func NewSampler(opts SampleOptions) *Sampler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	return &Sampler{opts: opts, now: time.Now, counts: make(map[string]int)}
+}
+
+// Allow reports whether the next entry of the key is kept, a dropped entry is counted
+func (s *Sampler) Allow(key string) bool {
+	s.mu.Lock()
+	now := s.now()
+	if now.Sub(s.windowStart) >= s.opts.Interval {
+		// a new window forgets every key, which also bounds the memory used by distinct keys
+		s.windowStart = now
+		clear(s.counts)
+	}
+	s.counts[key]++
+	n := s.counts[key]
+	s.mu.Unlock()
+
+	if n <= s.opts.First {
+		return true
+	}
+	if s.opts.Thereafter > 0 && (n-s.opts.First)%s.opts.Thereafter == 0 {
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+// Dropped returns how many entries the sampler dropped since it was created
+func (s *Sampler) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Sample wraps a logger so entries are sampled per level and message
+// Entries below level are dropped before sampling, so they don't use up the sample of a key; nil is Level()
+func Sample(logger Logger, s *Sampler, level *LevelVar) Logger {
+	if level == nil {
+		level = Level()
+	}
+	return &sampleLogger{logger: logger, sampler: s, level: level}
+}
+
+type sampleLogger struct {
+	logger  Logger
+	sampler *Sampler
+	level   *LevelVar
+}
+
+// allow reports whether an entry is enabled and kept by the sampler
+func (s *sampleLogger) allow(level LogLevel, key string) bool {
+	return level >= s.level.Get() && s.sampler.Allow(key)
+}
+
+func (s *sampleLogger) Debug(ctx context.Context, msg string, fields ...Field) {
+	if s.allow(LevelDebug, "debug:"+msg) {
+		s.logger.Debug(ctx, msg, fields...)
+	}
+}
+
+func (s *sampleLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	if s.allow(LevelInfo, "info:"+msg) {
+		s.logger.Info(ctx, msg, fields...)
+	}
+}
+
+func (s *sampleLogger) Warning(ctx context.Context, msg string, fields ...Field) {
+	if s.allow(LevelWarn, "warn:"+msg) {
+		s.logger.Warning(ctx, msg, fields...)
+	}
+}
+
+func (s *sampleLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	if s.allow(LevelError, "error:"+msg) {
+		s.logger.Error(ctx, msg, fields...)
+	}
+}
+
+func (s *sampleLogger) With(fields ...Field) Logger {
+	return &sampleLogger{logger: s.logger.With(fields...), sampler: s.sampler, level: s.level}
+}
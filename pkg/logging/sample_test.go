@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSampler(t *testing.T) {
+	s := NewSampler(SampleOptions{Interval: time.Second, First: 2, Thereafter: 3})
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	var kept []int
+	for i := 1; i <= 8; i++ {
+		if s.Allow("hot") {
+			kept = append(kept, i)
+		}
+	}
+	// the first 2, then every 3rd: entries 5 and 8
+	if len(kept) != 4 || kept[2] != 5 || kept[3] != 8 {
+		t.Errorf("Expected entries 1, 2, 5 and 8 to be kept, got %v", kept)
+	}
+	if s.Dropped() != 4 {
+		t.Errorf("Expected 4 dropped entries, got %d", s.Dropped())
+	}
+
+	if !s.Allow("other") {
+		t.Error("Expected keys to be sampled independently")
+	}
+	now = now.Add(time.Second)
+	if !s.Allow("hot") {
+		t.Error("Expected a new interval to keep the first entries again")
+	}
+}
+
+func TestSampleLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	sampler := NewSampler(SampleOptions{Interval: time.Hour, First: 1})
+	level := NewLevelVar(LevelInfo)
+	logger := Sample(NewZapLogger(zap.New(core)), sampler, level)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		logger.Info(ctx, "Incoming request")
+		logger.With(NewField("worker", i)).Error(ctx, "Worker job failed")
+	}
+
+	if logs.Len() != 2 || sampler.Dropped() != 4 {
+		t.Errorf("Expected one entry per message and 4 dropped, got %d and %d", logs.Len(), sampler.Dropped())
+	}
+
+	// entries below the level are not sampled, they don't take the first entry of their key
+	logger.Debug(ctx, "Cache miss")
+	if sampler.Dropped() != 4 {
+		t.Errorf("Expected a disabled entry not to count as dropped, got %d dropped", sampler.Dropped())
+	}
+	level.Set(LevelDebug)
+	logger.Debug(ctx, "Cache miss")
+	if logs.Len() != 3 {
+		t.Errorf("Expected the first enabled debug entry to be kept, got %d entries", logs.Len())
+	}
+}
@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	BackendSlog = "slog"
+	BackendZap  = "zap"
+
+	FormatJSON    = "json"
+	FormatText    = "text"
+	FormatConsole = "console" // zap's console encoder, the text handler for slog
+
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
+
+// Options configures a logger built by New
+type Options struct {
+	Backend   string    // BackendSlog or BackendZap
+	Level     *LevelVar // minimum level, change it to adjust the logger at runtime, Level() when nil
+	Format    string    // FormatJSON, FormatText or FormatConsole
+	Outputs   []string  // OutputStdout, OutputStderr and OutputFile
+	AddSource bool
+	File      RotateOptions  // used by OutputFile
+	Redact    *RedactOptions // masks sensitive fields and values when set
+	Sampler   *Sampler       // drops entries of hot log paths when set
+}
+
+// New builds a logger writing to every output, close the returned closer on exit to release the log file
+func New(opts Options) (Logger, io.Closer, error) {
+	if opts.Level == nil {
+		opts.Level = Level()
+	}
+	logger, closer, err := newBackend(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Redact != nil {
+		logger = Redact(logger, NewRedactor(*opts.Redact))
+	}
+	if opts.Sampler != nil {
+		// sample first so dropped entries are not redacted for nothing
+		logger = Sample(logger, opts.Sampler, opts.Level)
+	}
+	return logger, closer, nil
+}
+
+func newBackend(opts Options) (Logger, io.Closer, error) {
+	var writers []io.Writer
+	var closers multiCloser
+	for _, output := range opts.Outputs {
+		switch output {
+		case OutputStdout:
+			writers = append(writers, os.Stdout)
+		case OutputStderr:
+			writers = append(writers, os.Stderr)
+		case OutputFile:
+			file, err := OpenRotatingFile(opts.File)
+			if err != nil {
+				_ = closers.Close()
+				return nil, nil, err
+			}
+			writers = append(writers, file)
+			closers = append(closers, file)
+		default:
+			_ = closers.Close()
+			return nil, nil, fmt.Errorf("unknown log output %q", output)
+		}
+	}
+	if len(writers) == 0 {
+		writers = append(writers, os.Stdout)
+	}
+	out := io.MultiWriter(writers...)
+
+	switch opts.Backend {
+	case BackendSlog, "":
+		handlerOpts := &slog.HandlerOptions{AddSource: opts.AddSource, Level: slogLeveler{opts.Level}}
+		var handler slog.Handler = slog.NewTextHandler(out, handlerOpts)
+		if opts.Format == FormatJSON {
+			handler = slog.NewJSONHandler(out, handlerOpts)
+		}
+		return NewSlogLogger(slog.New(handler)), closers, nil
+	case BackendZap:
+		encoderCfg := zap.NewProductionEncoderConfig()
+		encoderCfg.TimeKey = "timestamp"
+		encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder := zapcore.NewConsoleEncoder(encoderCfg)
+		if opts.Format == FormatJSON {
+			encoder = zapcore.NewJSONEncoder(encoderCfg)
+		}
+		core := zapcore.NewCore(encoder, zapcore.AddSync(out), zapEnabler{opts.Level})
+		var zapOpts []zap.Option
+		if opts.AddSource {
+			zapOpts = append(zapOpts, zap.AddCaller())
+		}
+		return NewZapLogger(zap.New(core, zapOpts...)), closers, nil
+	default:
+		_ = closers.Close()
+		return nil, nil, fmt.Errorf("unknown log backend %q", opts.Backend)
+	}
+}
+
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var errs []error
+	for _, c := range m {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -40,30 +40,23 @@ func (s slogLogger) Error(ctx context.Context, msg string, fields ...Field) {
 	s.logWithSlog(ctx, LevelError, msg, fields...)
 }
 
-func (s slogLogger) logWithSlog(ctx context.Context, level LogLevel, msg string, fields ...Field) {
-	slogLevel := func(level LogLevel) slog.Level {
-		switch level {
-		case LevelDebug:
-			return slog.LevelDebug
-		case LevelInfo:
-			return slog.LevelInfo
-		case LevelWarn:
-			return slog.LevelWarn
-		case LevelError:
-			return slog.LevelError
-		default:
-			return slog.LevelInfo
-		}
-	}(level)
+func (s slogLogger) With(fields ...Field) Logger {
+	return &slogLogger{logger: slog.New(s.logger.Handler().WithAttrs(slogAttrs(fields)))}
+}
 
+func (s slogLogger) logWithSlog(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	slogLevel := toSlogLevel(level)
 	if !s.logger.Enabled(ctx, slogLevel) {
 		return
 	}
 
-	var attrs []slog.Attr
+	s.logger.LogAttrs(ctx, slogLevel, msg, slogAttrs(withContextFields(ctx, fields))...)
+}
+
+func slogAttrs(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
 	for _, field := range fields {
 		attrs = append(attrs, slog.Any(field.Key, field.Value))
 	}
-
-	s.logger.LogAttrs(ctx, slogLevel, msg, attrs...)
+	return attrs
 }
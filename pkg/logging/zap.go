@@ -71,20 +71,25 @@ func (z zapLogger) Error(ctx context.Context, msg string, fields ...Field) {
 	z.logWithZap(ctx, LevelError, msg, fields...)
 }
 
+func (z zapLogger) With(fields ...Field) Logger {
+	return &zapLogger{logger: z.logger.With(zapFields(fields)...)}
+}
+
 func (z zapLogger) logWithZap(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	zapLevel := toZapLevel(level)
+
+	// skip building fields for disabled levels
+	entry := z.logger.Check(zapLevel, msg)
+	if entry == nil {
+		return
+	}
+	entry.Write(zapFields(withContextFields(ctx, fields))...)
+}
+
+func zapFields(fields []Field) []zap.Field {
 	zapFields := make([]zap.Field, len(fields))
 	for i, field := range fields {
 		zapFields[i] = zap.Any(field.Key, field.Value)
 	}
-
-	switch {
-	case level <= LevelDebug:
-		z.logger.Debug(msg, zapFields...)
-	case level <= LevelInfo:
-		z.logger.Info(msg, zapFields...)
-	case level <= LevelWarn:
-		z.logger.Warn(msg, zapFields...)
-	default:
-		z.logger.Error(msg, zapFields...)
-	}
+	return zapFields
 }
@@ -14,7 +14,7 @@ import (
 )
 
 func NewSESClient(cfg *config.SES) (*sesv2.Client, error) {
-	awscfg, err := awsconfig.LoadDefaultConfig(context.TODO(), awsconfig.WithRegion("us-west-2"))
+	awscfg, err := awsconfig.LoadDefaultConfig(context.TODO(), awsconfig.WithRegion(cfg.Region))
 	if err != nil {
 		logging.Error(context.Background(), fmt.Sprintf("unable to load SDK config, %v", err))
 		return nil, err
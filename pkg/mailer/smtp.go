@@ -18,7 +18,7 @@ func NewSmtp(cfg *config.SMTP) (*mail.Client, error) {
 		mail.WithSMTPAuth(mail.SMTPAuthType(cfg.AuthType)),
 		mail.WithTLSPortPolicy(mail.TLSPolicy(cfg.WithTLSPortPolicy)),
 		mail.WithUsername(cfg.Username),
-		mail.WithPassword(cfg.Password),
+		mail.WithPassword(cfg.Password.Value()),
 		mail.WithPort(cfg.Port),
 	)
 
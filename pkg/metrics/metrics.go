@@ -0,0 +1,101 @@
+// Package metrics collects the application metrics and exposes them in the Prometheus text format
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Task outcomes used as the status label of the task metrics
+const (
+	TaskSuccess = "success"
+	TaskFailure = "failure"
+)
+
+// Metrics holds the application collectors on their own registry
+type Metrics struct {
+	registry     *prometheus.Registry
+	httpDuration *prometheus.HistogramVec
+	tasks        *prometheus.CounterVec
+	taskDuration *prometheus.HistogramVec
+}
+
+// New creates the application collectors along with the Go runtime and process ones
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests by method, route pattern and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		tasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_processed_total",
+			Help: "Tasks processed by task type and outcome.",
+		}, []string{"task_type", "status"}),
+		taskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "task_duration_seconds",
+			Help:    "Duration of task processing by task type.",
+			Buckets: []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+		}, []string{"task_type"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.tasks,
+		m.taskDuration,
+	)
+	return m
+}
+
+var std = New()
+
+// Default returns the process wide metrics used by the deliveries and the kernel
+func Default() *Metrics {
+	return std
+}
+
+// Registerer lets modules register their own collectors next to the application ones
+func (m *Metrics) Registerer() prometheus.Registerer {
+	return m.registry
+}
+
+// Handler serves every collected metric in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTP records a request, route is the route pattern like /users/:id, never the raw path
+func (m *Metrics) ObserveHTTP(method, route string, status int, duration time.Duration) {
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveTask records a processed task, a nil err counts as a success
+func (m *Metrics) ObserveTask(taskType string, err error, duration time.Duration) {
+	status := TaskSuccess
+	if err != nil {
+		status = TaskFailure
+	}
+	m.tasks.WithLabelValues(taskType, status).Inc()
+	m.taskDuration.WithLabelValues(taskType).Observe(duration.Seconds())
+}
+
+// DropCounter counts the log entries it dropped, like *logging.Sampler or the access log
+type DropCounter interface {
+	Dropped() uint64
+}
+
+// RegisterLogDrops exports the entries dropped by a sampler as log_entries_dropped_total, labelled with source
+func (m *Metrics) RegisterLogDrops(source string, counter DropCounter) error {
+	return m.registry.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name:        "log_entries_dropped_total",
+		Help:        "Log entries dropped by sampling.",
+		ConstLabels: prometheus.Labels{"source": source},
+	}, func() float64 { return float64(counter.Dropped()) }))
+}
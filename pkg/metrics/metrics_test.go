@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestObserve(t *testing.T) {
+	m := New()
+	m.ObserveHTTP("GET", "/users/:id", 200, 20*time.Millisecond)
+	m.ObserveHTTP("GET", "/users/:id", 200, 30*time.Millisecond)
+	m.ObserveTask("email:send", nil, time.Second)
+	m.ObserveTask("email:send", errors.New("smtp down"), time.Second)
+	m.ObserveTask("email:send", errors.New("smtp down"), time.Second)
+
+	if n := testutil.CollectAndCount(m.httpDuration); n != 1 {
+		t.Errorf("Expected 1 request series, got %d", n)
+	}
+	if v := testutil.ToFloat64(m.tasks.WithLabelValues("email:send", TaskFailure)); v != 2 {
+		t.Errorf("Expected 2 failed tasks, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.tasks.WithLabelValues("email:send", TaskSuccess)); v != 1 {
+		t.Errorf("Expected 1 successful task, got %v", v)
+	}
+}
+
+type fakePool struct{ queued, workers int }
+
+func (p fakePool) QueueLen() int    { return p.queued }
+func (p fakePool) WorkerCount() int { return p.workers }
+
+type fakeRedis struct{}
+
+func (fakeRedis) PoolStats() *redis.PoolStats {
+	return &redis.PoolStats{Hits: 7, TotalConns: 3, IdleConns: 2}
+}
+
+type fakeDrops uint64
+
+func (d fakeDrops) Dropped() uint64 { return uint64(d) }
+
+func TestHandler(t *testing.T) {
+	m := New()
+	if err := m.RegisterLogDrops("access_log", fakeDrops(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterWorkerPool("mailer", fakePool{queued: 4, workers: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterWorkerPool("mailer", fakePool{}); err == nil {
+		t.Error("Expected registering the same pool twice to fail")
+	}
+	if err := m.RegisterRedis("cache", fakeRedis{}); err != nil {
+		t.Fatal(err)
+	}
+	m.ObserveHTTP("POST", "/users", 201, time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`workerpool_queue_depth{pool="mailer"} 4`,
+		`workerpool_workers{pool="mailer"} 2`,
+		`redis_pool_hits_total{client="cache"} 7`,
+		`redis_pool_total_connections{client="cache"} 3`,
+		`http_request_duration_seconds_count{method="POST",route="/users",status="201"} 1`,
+		`log_entries_dropped_total{source="access_log"} 5`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the exposition to contain %s", want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+)
+
+// RegisterDB exports the sql.DBStats of the connection pool, labelled with db_name
+func (m *Metrics) RegisterDB(name string, db *sql.DB) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// PoolStater is a redis client exposing its pool statistics, like *redis.Client
+type PoolStater interface {
+	PoolStats() *redis.PoolStats
+}
+
+// RegisterRedis exports the redis.PoolStats of the client, labelled with client
+func (m *Metrics) RegisterRedis(name string, client PoolStater) error {
+	return m.registry.Register(newRedisCollector(name, client))
+}
+
+// WorkerPool is a pool exposing its queue depth and size, like *workerpool.WorkerPool
+type WorkerPool interface {
+	QueueLen() int
+	WorkerCount() int
+}
+
+// RegisterWorkerPool exports the queue depth and the worker count of the pool, labelled with pool
+func (m *Metrics) RegisterWorkerPool(name string, pool WorkerPool) error {
+	labels := prometheus.Labels{"pool": name}
+	depth := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "workerpool_queue_depth",
+		Help:        "Jobs waiting in the worker pool queue.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(pool.QueueLen()) })
+	workers := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "workerpool_workers",
+		Help:        "Workers of the worker pool.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(pool.WorkerCount()) })
+
+	if err := m.registry.Register(depth); err != nil {
+		return err
+	}
+	if err := m.registry.Register(workers); err != nil {
+		m.registry.Unregister(depth)
+		return err
+	}
+	return nil
+}
+
+// redisCollector reads the pool statistics of a redis client on every scrape
+type redisCollector struct {
+	client PoolStater
+
+	hits, misses, timeouts       *prometheus.Desc
+	totalConns, idleConns, stale *prometheus.Desc
+}
+
+func newRedisCollector(name string, client PoolStater) *redisCollector {
+	labels := prometheus.Labels{"client": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc("redis_pool_"+metric, help, nil, labels)
+	}
+	return &redisCollector{
+		client:     client,
+		hits:       desc("hits_total", "Times a free connection was found in the pool."),
+		misses:     desc("misses_total", "Times a free connection was not found in the pool."),
+		timeouts:   desc("timeouts_total", "Times a wait for a connection timed out."),
+		totalConns: desc("total_connections", "Connections in the pool."),
+		idleConns:  desc("idle_connections", "Idle connections in the pool."),
+		stale:      desc("stale_connections_total", "Stale connections removed from the pool."),
+	}
+}
+
+func (c *redisCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.stale
+}
+
+func (c *redisCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.stale, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,110 @@
+package module
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Sort orders the registered modules so every module comes after its dependencies
+// Modules without a dependency between them keep their registration order
+// It returns an error when a dependency is not registered or when dependencies form a cycle
+func (r *Registry) Sort() error {
+	index := make(map[string]int, len(r.modules))
+	for i, mdl := range r.modules {
+		name := mdl.GetInfo().Name
+		if _, exists := index[name]; exists {
+			return fmt.Errorf("module %s is registered more than once", name)
+		}
+		index[name] = i
+	}
+
+	for _, mdl := range r.modules {
+		info := mdl.GetInfo()
+		for _, dep := range info.Dependencies {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("module %s depends on unregistered module %s", info.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(r.modules))
+	sorted := make([]IModule, 0, len(r.modules))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		info := r.modules[i].GetInfo()
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("module dependency cycle: %s -> %s", strings.Join(path, " -> "), info.Name)
+		}
+
+		state[i] = visiting
+		path = append(path, info.Name)
+		for _, dep := range info.Dependencies {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		sorted = append(sorted, r.modules[i])
+		return nil
+	}
+
+	for i := range r.modules {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+
+	r.modules = sorted
+	return nil
+}
+
+// Resolve calls Resolve on every module implementing Resolver in registry order
+// Call Sort first so dependencies are resolved before their dependents
+func (r *Registry) Resolve() error {
+	for _, mdl := range r.modules {
+		if resolver, ok := mdl.(Resolver); ok {
+			if err := resolver.Resolve(r); err != nil {
+				return fmt.Errorf("module %s: resolve: %w", mdl.GetInfo().Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Lookup returns the public service of the named module as type T
+// The module must implement Provider and its service must be assignable to T
+//
+// Example:
+//
+//	svc, err := module.Lookup[domain.Service](registry, "Example")
+func Lookup[T any](r *Registry, name string) (T, error) {
+	var zero T
+
+	mdl, ok := r.Get(name)
+	if !ok {
+		return zero, fmt.Errorf("module %s is not registered", name)
+	}
+
+	provider, ok := mdl.(Provider)
+	if !ok {
+		return zero, fmt.Errorf("module %s does not provide a service", name)
+	}
+
+	svc, ok := provider.Provide().(T)
+	if !ok {
+		return zero, fmt.Errorf("module %s provides %T, not %s", name, provider.Provide(), reflect.TypeFor[T]())
+	}
+	return svc, nil
+}
@@ -0,0 +1,27 @@
+package module
+
+import (
+	"context"
+
+	"github.com/fatkulnurk/gostarter/pkg/health"
+)
+
+// Lifecycle is an optional interface for modules that own resources
+// OnStart is called after the module is registered and before the delivery starts serving,
+// OnStop is called on shutdown so the module can release what it opened
+type Lifecycle interface {
+	OnStart(ctx context.Context) error
+	OnStop(ctx context.Context) error
+}
+
+// HealthChecker is an optional interface for modules that can report their own health
+// The check is part of the readiness probe under the module name and is critical
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheckProvider is an optional interface for modules that register several checks,
+// each with its own name, timeout and criticality
+type HealthCheckProvider interface {
+	HealthChecks() []health.Check
+}
@@ -1,5 +1,10 @@
 package module
 
+import (
+	"github.com/fatkulnurk/gostarter/pkg/cli"
+	"github.com/fatkulnurk/gostarter/shared/infrastructure"
+)
+
 // IModule defines the contract for application modules in the clean architecture pattern
 // Each module must implement these methods to be registered with the application
 type IModule interface {
@@ -16,6 +21,28 @@ type IModule interface {
 // Module contains basic information about a module
 // including its name and routing prefix for API endpoints
 type Module struct {
-	Name   string // The display name of the module
-	Prefix string // The URL prefix used for routing
+	Name         string   // The display name of the module
+	Prefix       string   // The URL prefix used for routing
+	Dependencies []string // Names of the modules this module depends on
+}
+
+// Provider is an optional interface for modules that expose a public service to their dependents
+// Dependents retrieve it with Lookup instead of importing the module's internal packages
+type Provider interface {
+	Provide() any
+}
+
+// Resolver is an optional interface for modules that need services of their dependencies
+// Resolve is called in dependency order, after every dependency has been resolved
+type Resolver interface {
+	Resolve(registry *Registry) error
+}
+
+// Factory creates a module from the shared adapter and the delivery of the running mode
+type Factory func(adapter *infrastructure.Adapter, delivery *infrastructure.Delivery) IModule
+
+// CommandProvider is an optional interface for modules that contribute their own CLI subcommands
+// The commands are available under the module prefix, e.g. "gostarter example <command>"
+type CommandProvider interface {
+	Commands() []*cli.Command
 }
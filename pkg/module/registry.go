@@ -0,0 +1,111 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fatkulnurk/gostarter/pkg/health"
+)
+
+// Registry holds every module registered with the application, in registration order
+type Registry struct {
+	modules []IModule
+	started []Lifecycle
+	names   []string
+}
+
+// NewRegistry creates an empty module registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Add appends modules to the registry
+func (r *Registry) Add(modules ...IModule) {
+	r.modules = append(r.modules, modules...)
+}
+
+// Modules returns the registered modules in registration order
+func (r *Registry) Modules() []IModule {
+	return r.modules
+}
+
+// Get returns the module with the given name
+func (r *Registry) Get(name string) (IModule, bool) {
+	for _, mdl := range r.modules {
+		if mdl.GetInfo().Name == name {
+			return mdl, true
+		}
+	}
+	return nil, false
+}
+
+// Start calls OnStart on every module implementing Lifecycle in registration order
+// If a module fails to start, the modules already started are stopped in reverse order
+// and the start error is returned
+func (r *Registry) Start(ctx context.Context) error {
+	for _, mdl := range r.modules {
+		lc, ok := mdl.(Lifecycle)
+		if !ok {
+			continue
+		}
+
+		name := mdl.GetInfo().Name
+		if err := lc.OnStart(ctx); err != nil {
+			startErr := fmt.Errorf("module %s: start: %w", name, err)
+			if stopErr := r.Stop(ctx); stopErr != nil {
+				return errors.Join(startErr, stopErr)
+			}
+			return startErr
+		}
+		r.started = append(r.started, lc)
+		r.names = append(r.names, name)
+	}
+	return nil
+}
+
+// Stop calls OnStop on every started module in reverse order
+// Every module is stopped even if one fails, all errors are returned joined
+func (r *Registry) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(r.started) - 1; i >= 0; i-- {
+		if err := r.started[i].OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("module %s: stop: %w", r.names[i], err))
+		}
+	}
+	r.started = nil
+	r.names = nil
+	return errors.Join(errs...)
+}
+
+// HealthCheck runs HealthCheck on every module implementing HealthChecker
+// and returns the result keyed by module name, a nil error means healthy
+func (r *Registry) HealthCheck(ctx context.Context) map[string]error {
+	result := make(map[string]error)
+	for _, mdl := range r.modules {
+		if hc, ok := mdl.(HealthChecker); ok {
+			result[mdl.GetInfo().Name] = hc.HealthCheck(ctx)
+		}
+	}
+	return result
+}
+
+// HealthChecks returns the checks of every module for the readiness probe
+// A HealthChecker becomes a critical check named after the module, the checks of a HealthCheckProvider
+// are prefixed with the module name, like "example:payment-api"
+func (r *Registry) HealthChecks() []health.Check {
+	var checks []health.Check
+	for _, mdl := range r.modules {
+		name := mdl.GetInfo().Name
+		if hc, ok := mdl.(HealthChecker); ok {
+			checks = append(checks, health.Check{Name: name, Func: hc.HealthCheck, Critical: true})
+		}
+		if provider, ok := mdl.(HealthCheckProvider); ok {
+			for _, check := range provider.HealthChecks() {
+				check.Name = name + ":" + check.Name
+				checks = append(checks, check)
+			}
+		}
+	}
+	return checks
+}
@@ -0,0 +1,168 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/health"
+)
+
+type fakeModule struct {
+	name     string
+	startErr error
+	events   *[]string
+}
+
+func (m *fakeModule) GetInfo() *Module  { return &Module{Name: m.name, Prefix: m.name} }
+func (m *fakeModule) RegisterHTTP()     {}
+func (m *fakeModule) RegisterTask()     {}
+func (m *fakeModule) RegisterSchedule() {}
+
+func (m *fakeModule) OnStart(ctx context.Context) error {
+	*m.events = append(*m.events, "start:"+m.name)
+	return m.startErr
+}
+
+func (m *fakeModule) OnStop(ctx context.Context) error {
+	*m.events = append(*m.events, "stop:"+m.name)
+	return nil
+}
+
+func TestRegistryStartStopOrder(t *testing.T) {
+	var events []string
+	r := NewRegistry()
+	r.Add(&fakeModule{name: "a", events: &events}, &fakeModule{name: "b", events: &events})
+
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start modules: %v", err)
+	}
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatalf("Failed to stop modules: %v", err)
+	}
+
+	expected := []string{"start:a", "start:b", "stop:b", "stop:a"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected %v, got %v", expected, events)
+	}
+}
+
+func TestRegistryStartFailureStopsStartedModules(t *testing.T) {
+	var events []string
+	startErr := errors.New("boom")
+	r := NewRegistry()
+	r.Add(
+		&fakeModule{name: "a", events: &events},
+		&fakeModule{name: "b", events: &events, startErr: startErr},
+		&fakeModule{name: "c", events: &events},
+	)
+
+	err := r.Start(context.Background())
+	if !errors.Is(err, startErr) {
+		t.Fatalf("Expected start error, got %v", err)
+	}
+
+	expected := []string{"start:a", "start:b", "stop:a"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected %v, got %v", expected, events)
+	}
+}
+
+type dependentModule struct {
+	fakeModule
+	deps []string
+}
+
+func (m *dependentModule) GetInfo() *Module {
+	return &Module{Name: m.name, Prefix: m.name, Dependencies: m.deps}
+}
+
+func (m *dependentModule) Provide() any { return m.name + "-service" }
+
+func moduleNames(r *Registry) []string {
+	var names []string
+	for _, mdl := range r.Modules() {
+		names = append(names, mdl.GetInfo().Name)
+	}
+	return names
+}
+
+func TestRegistrySortByDependencies(t *testing.T) {
+	r := NewRegistry()
+	r.Add(
+		&dependentModule{fakeModule: fakeModule{name: "order"}, deps: []string{"user", "product"}},
+		&dependentModule{fakeModule: fakeModule{name: "user"}},
+		&dependentModule{fakeModule: fakeModule{name: "product"}, deps: []string{"user"}},
+	)
+
+	if err := r.Sort(); err != nil {
+		t.Fatalf("Failed to sort modules: %v", err)
+	}
+
+	expected := []string{"user", "product", "order"}
+	if names := moduleNames(r); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+}
+
+func TestRegistrySortErrors(t *testing.T) {
+	missing := NewRegistry()
+	missing.Add(&dependentModule{fakeModule: fakeModule{name: "order"}, deps: []string{"user"}})
+	if err := missing.Sort(); err == nil {
+		t.Error("Expected error for missing dependency")
+	}
+
+	cycle := NewRegistry()
+	cycle.Add(
+		&dependentModule{fakeModule: fakeModule{name: "a"}, deps: []string{"b"}},
+		&dependentModule{fakeModule: fakeModule{name: "b"}, deps: []string{"a"}},
+	)
+	if err := cycle.Sort(); err == nil {
+		t.Error("Expected error for dependency cycle")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	r := NewRegistry()
+	r.Add(&dependentModule{fakeModule: fakeModule{name: "user"}})
+
+	svc, err := Lookup[string](r, "user")
+	if err != nil {
+		t.Fatalf("Failed to lookup service: %v", err)
+	}
+	if svc != "user-service" {
+		t.Errorf("Expected user-service, got %s", svc)
+	}
+
+	if _, err := Lookup[int](r, "user"); err == nil {
+		t.Error("Expected error for wrong service type")
+	}
+	if _, err := Lookup[string](r, "unknown"); err == nil {
+		t.Error("Expected error for unknown module")
+	}
+}
+
+type healthModule struct {
+	fakeModule
+}
+
+func (m *healthModule) HealthCheck(ctx context.Context) error { return nil }
+
+func (m *healthModule) HealthChecks() []health.Check {
+	return []health.Check{{Name: "payment-api", Func: m.HealthCheck}}
+}
+
+func TestRegistryHealthChecks(t *testing.T) {
+	r := NewRegistry()
+	r.Add(&fakeModule{name: "plain"})
+	r.Add(&healthModule{fakeModule{name: "billing"}})
+
+	var names []string
+	for _, check := range r.HealthChecks() {
+		names = append(names, check.Name)
+	}
+	if want := []string{"billing", "billing:payment-api"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected checks %v, got %v", want, names)
+	}
+}
@@ -7,9 +7,13 @@ import (
 
 	"github.com/fatkulnurk/gostarter/pkg/config"
 	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/tracing"
 
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func NewAsynqClient(cfg *config.Queue, redis *redis.Client) (*asynq.Client, error) {
@@ -32,11 +36,29 @@ func NewAsynqQueue(client *asynq.Client) Queue {
 	return &AsynqQueue{client: client}
 }
 
-func (q *AsynqQueue) Enqueue(ctx context.Context, taskName string, payload any, opts ...Option) (*OutputEnqueue, error) {
+func (q *AsynqQueue) Enqueue(ctx context.Context, taskName string, payload any, opts ...Option) (_ *OutputEnqueue, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "send "+taskName,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingSystem, semconv.MessagingOperationTypeSend, taskTypeKey.String(taskName)),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
+	// carry the request identity and the producer span to the worker, see carriesMetadata
+	if newOptions(opts...).carriesMetadata() {
+		if data, err = InjectMetadata(data, contextMetadata(ctx)); err != nil {
+			return nil, err
+		}
+	}
 
 	task := asynq.NewTask(taskName, data)
 	aOpts := toAsynqOptions(opts...)
@@ -44,5 +66,6 @@ func (q *AsynqQueue) Enqueue(ctx context.Context, taskName string, payload any,
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(semconv.MessagingMessageID(tInfo.ID), semconv.MessagingDestinationName(tInfo.Queue))
 	return &OutputEnqueue{TaskID: tInfo.ID, Payload: data, Options: opts}, nil
 }
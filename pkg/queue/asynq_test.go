@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/requestid"
+	"github.com/fatkulnurk/gostarter/pkg/tracing"
+	"github.com/fatkulnurk/gostarter/pkg/tracing/tracingtest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestQueue returns a queue on an in-memory redis and an inspector of its broker
+func newTestQueue(t *testing.T) (Queue, *asynq.Inspector) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewAsynqQueue(asynq.NewClientFromRedisClient(client)), asynq.NewInspectorFromRedisClient(client)
+}
+
+func pendingTasks(t *testing.T, inspector *asynq.Inspector) []*asynq.TaskInfo {
+	t.Helper()
+	tasks, err := inspector.ListPendingTasks("default")
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %v", err)
+	}
+	return tasks
+}
+
+func TestEnqueueUniqueAcrossRequests(t *testing.T) {
+	q, inspector := newTestQueue(t)
+	payload := map[string]int{"user_id": 7}
+
+	first := requestid.NewContext(context.Background(), "req-1")
+	if _, err := q.Enqueue(first, "user:sync", payload, Unique(time.Minute)); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	second := requestid.NewContext(context.Background(), "req-2")
+	if _, err := q.Enqueue(second, "user:sync", payload, Unique(time.Minute)); !errors.Is(err, asynq.ErrDuplicateTask) {
+		t.Errorf("Expected the second task to be a duplicate, got %v", err)
+	}
+
+	tasks := pendingTasks(t, inspector)
+	if len(tasks) != 1 {
+		t.Fatalf("Expected one task, got %d", len(tasks))
+	}
+	if meta := ExtractMetadata(tasks[0].Payload); meta != nil {
+		t.Errorf("Expected a unique task without metadata, got %v", meta)
+	}
+}
+
+func TestEnqueueMetadata(t *testing.T) {
+	q, inspector := newTestQueue(t)
+	ctx := requestid.NewContext(context.Background(), "req-1")
+
+	if _, err := q.Enqueue(ctx, "user:sync", map[string]int{"user_id": 7}); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if _, err := q.Enqueue(ctx, "user:sync", map[string]int{"user_id": 8}, WithoutMetadata()); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	withMeta := 0
+	for _, task := range pendingTasks(t, inspector) {
+		if ExtractMetadata(task.Payload)[requestid.Field] == "req-1" {
+			withMeta++
+		}
+	}
+	if withMeta != 1 {
+		t.Errorf("Expected only the first task to carry the request id, got %d", withMeta)
+	}
+}
+
+func TestEnqueueUniqueWithTracing(t *testing.T) {
+	tracingtest.Capture(t)
+	q, inspector := newTestQueue(t)
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "POST /users/7/sync")
+	defer span.End()
+	for i := 0; i < 2; i++ {
+		_, err := q.Enqueue(ctx, "user:sync", map[string]int{"user_id": 7}, Unique(time.Minute))
+		if i == 1 && !errors.Is(err, asynq.ErrDuplicateTask) {
+			t.Errorf("Expected the second task to be a duplicate, got %v", err)
+		}
+	}
+
+	if tasks := pendingTasks(t, inspector); len(tasks) != 1 {
+		t.Errorf("Expected one task, got %d", len(tasks))
+	}
+}
@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/requestid"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// MetadataKey is the payload key metadata travels under, it is reserved in JSON object payloads
+const MetadataKey = "_meta"
+
+// Metadata is the request identity and the trace context carried from the enqueuing context to the task handler
+type Metadata map[string]string
+
+// contextMetadata collects the metadata of the enqueuing context
+// The traceparent names the producer span, so it differs on every call, see carriesMetadata
+func contextMetadata(ctx context.Context) Metadata {
+	meta := Metadata{}
+	if id := requestid.FromContext(ctx); id != "" {
+		meta[requestid.Field] = id
+	}
+	// traceparent and tracestate, nothing when tracing is not set up
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(meta))
+	return meta
+}
+
+// metadataContext restores the metadata on the handler context
+func metadataContext(ctx context.Context, meta Metadata) context.Context {
+	if id := meta[requestid.Field]; id != "" {
+		ctx = requestid.NewContext(ctx, id)
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(meta))
+}
+
+// InjectMetadata adds the metadata to a JSON object payload under MetadataKey
+// Any other payload, like an array or a string, is returned unchanged because it has no room for it
+func InjectMetadata(payload []byte, meta Metadata) ([]byte, error) {
+	if len(meta) == 0 || !isObject(payload) {
+		return payload, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	fields[MetadataKey] = encoded
+	return json.Marshal(fields)
+}
+
+// ExtractMetadata returns the metadata of a payload, nil when it has none
+func ExtractMetadata(payload []byte) Metadata {
+	if !isObject(payload) {
+		return nil
+	}
+	var envelope struct {
+		Meta Metadata `json:"_meta"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil
+	}
+	return envelope.Meta
+}
+
+func isObject(payload []byte) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// MetadataMiddleware restores the metadata of the enqueuing request on the task context
+// and attaches the task type and ID to every log call made with it
+func MetadataMiddleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		ctx = metadataContext(ctx, ExtractMetadata(task.Payload()))
+
+		fields := []logging.Field{logging.NewField("task_type", task.Type())}
+		if id, ok := asynq.GetTaskID(ctx); ok {
+			fields = append(fields, logging.NewField("task_id", id))
+		}
+		return next.ProcessTask(logging.WithFields(ctx, fields...), task)
+	})
+}
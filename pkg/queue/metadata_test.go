@@ -0,0 +1,65 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/requestid"
+
+	"github.com/hibiken/asynq"
+)
+
+func TestInjectMetadata(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "req-1")
+
+	payload, err := InjectMetadata([]byte(`{"user_id": 7}`), contextMetadata(ctx))
+	if err != nil {
+		t.Fatalf("Failed to inject metadata: %v", err)
+	}
+
+	var body struct {
+		UserID int `json:"user_id"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil || body.UserID != 7 {
+		t.Errorf("Expected the payload to keep its fields, got %s", payload)
+	}
+	if meta := ExtractMetadata(payload); meta[requestid.Field] != "req-1" {
+		t.Errorf("Expected request id req-1 in the metadata, got %v", meta)
+	}
+
+	// payloads that are not objects have no room for metadata
+	for _, raw := range []string{`[1,2]`, `"text"`, `null`} {
+		out, err := InjectMetadata([]byte(raw), contextMetadata(ctx))
+		if err != nil || string(out) != raw {
+			t.Errorf("Expected %s unchanged, got %s, %v", raw, out, err)
+		}
+	}
+}
+
+func TestMetadataMiddleware(t *testing.T) {
+	payload, _ := InjectMetadata([]byte(`{}`), Metadata{requestid.Field: "req-1"})
+
+	var id string
+	var fields []logging.Field
+	handler := MetadataMiddleware(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		id = requestid.FromContext(ctx)
+		fields = logging.ContextFields(ctx)
+		return nil
+	}))
+	if err := handler.ProcessTask(context.Background(), asynq.NewTask("email:send", payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "req-1" {
+		t.Errorf("Expected request id req-1 on the task context, got %q", id)
+	}
+	keys := map[string]any{}
+	for _, f := range fields {
+		keys[f.Key] = f.Value
+	}
+	if keys[requestid.Field] != "req-1" || keys["task_type"] != "email:send" {
+		t.Errorf("Expected request id and task type log fields, got %v", fields)
+	}
+}
@@ -0,0 +1,22 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/metrics"
+
+	"github.com/hibiken/asynq"
+)
+
+// MetricsMiddleware records the outcome and the duration of every processed task by task type
+func MetricsMiddleware(m *metrics.Metrics) asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+			start := time.Now()
+			err := next.ProcessTask(ctx, task)
+			m.ObserveTask(task.Type(), err, time.Since(start))
+			return err
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/metrics"
+
+	"github.com/hibiken/asynq"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := metrics.New()
+	handler := MetricsMiddleware(m)(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		if string(task.Payload()) == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	}))
+
+	for _, payload := range []string{"ok", "ok", "fail"} {
+		_ = handler.ProcessTask(context.Background(), asynq.NewTask("email:send", []byte(payload)))
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`task_processed_total{status="success",task_type="email:send"} 2`,
+		`task_processed_total{status="failure",task_type="email:send"} 1`,
+		`task_duration_seconds_count{task_type="email:send"} 3`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the exposition to contain %s", want)
+		}
+	}
+}
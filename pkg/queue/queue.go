@@ -33,6 +33,8 @@ type options struct {
 	taskID    string
 	retention time.Duration
 	group     string
+
+	noMetadata bool
 }
 
 // MaxRetry sets the maximum number of retry attempts for a task
@@ -115,12 +117,33 @@ func Group(name string) Option {
 	}
 }
 
-// toAsynqOptions converts our internal options to asynq options
-func toAsynqOptions(opts ...Option) []asynq.Option {
+// WithoutMetadata enqueues the payload exactly as given, without the request metadata under _meta
+// Use it for handlers that decode with DisallowUnknownFields or into a map
+// Example: queue.Enqueue(ctx, "webhook:forward", payload, queue.WithoutMetadata())
+func WithoutMetadata() Option {
+	return func(o *options) {
+		o.noMetadata = true
+	}
+}
+
+func newOptions(opts ...Option) *options {
 	o := &options{}
 	for _, opt := range opts {
 		opt(o)
 	}
+	return o
+}
+
+// carriesMetadata reports whether the request metadata may be added to the payload
+// asynq derives the key of a Unique task from its payload and a TaskID task is deduplicated by its ID,
+// per request metadata would make every payload different and break both
+func (o *options) carriesMetadata() bool {
+	return !o.noMetadata && o.unique == 0 && o.taskID == ""
+}
+
+// toAsynqOptions converts our internal options to asynq options
+func toAsynqOptions(opts ...Option) []asynq.Option {
+	o := newOptions(opts...)
 
 	var aOpts []asynq.Option
 	if o.maxRetry > 0 {
@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/tracing"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	messagingSystem = semconv.MessagingSystemKey.String("asynq")
+	taskTypeKey     = attribute.Key("asynq.task.type")
+)
+
+// TracingMiddleware starts a consumer span for every task, continuing the trace of the enqueuing request
+// Register it after MetadataMiddleware, which restores that trace on the task context
+func TracingMiddleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		attrs := []attribute.KeyValue{messagingSystem, semconv.MessagingOperationTypeProcess, taskTypeKey.String(task.Type())}
+		if id, ok := asynq.GetTaskID(ctx); ok {
+			attrs = append(attrs, semconv.MessagingMessageID(id))
+		}
+		if queue, ok := asynq.GetQueueName(ctx); ok {
+			attrs = append(attrs, semconv.MessagingDestinationName(queue))
+		}
+
+		ctx, span := tracing.Tracer().Start(ctx, "process "+task.Type(),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+		if id := tracing.TraceID(ctx); id != "" {
+			ctx = logging.WithFields(ctx, logging.NewField("trace_id", id))
+		}
+
+		err := next.ProcessTask(ctx, task)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	})
+}
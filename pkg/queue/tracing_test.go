@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/tracing"
+	"github.com/fatkulnurk/gostarter/pkg/tracing/tracingtest"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingResumesEnqueuingTrace(t *testing.T) {
+	spans := tracingtest.Capture(t)
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "POST /emails")
+	payload, err := InjectMetadata([]byte(`{"to":"a@example.com"}`), contextMetadata(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	var handlerTrace string
+	handler := MetadataMiddleware(TracingMiddleware(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		handlerTrace = tracing.TraceID(ctx)
+		return nil
+	})))
+	if err := handler.ProcessTask(context.Background(), asynq.NewTask("email:send", payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	span := tracingtest.Span(t, spans, "process email:send")
+	if span.SpanKind != trace.SpanKindConsumer {
+		t.Errorf("Expected a consumer span, got %s", span.SpanKind)
+	}
+	if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Expected the enqueuing span as parent, got %s", span.Parent.SpanID())
+	}
+	if handlerTrace != parent.SpanContext().TraceID().String() {
+		t.Errorf("Expected the handler to run in the enqueuing trace, got %s", handlerTrace)
+	}
+}
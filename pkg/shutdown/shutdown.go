@@ -0,0 +1,192 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+)
+
+// Func releases a resource or drains a component, it must return once ctx is done
+type Func func(ctx context.Context) error
+
+type hook struct {
+	name    string
+	timeout time.Duration
+	fn      Func
+}
+
+// Coordinator waits for a termination signal and runs the registered shutdown hooks
+// Hooks run one by one in reverse registration order, so register resources in the order they are created:
+// adapters first, then modules, then the delivery that accepts work
+type Coordinator struct {
+	mu       sync.Mutex
+	hooks    []hook
+	policies map[string]Policy
+	running  map[string]bool
+	abort    chan error
+	aborted  sync.Once
+}
+
+// Policy decides what happens to the process when one of its components fails
+type Policy string
+
+const (
+	// PolicyShutdown shuts the whole process down when the component fails, this is the default
+	PolicyShutdown Policy = "shutdown"
+	// PolicyContinue logs the failure and keeps the other components running
+	PolicyContinue Policy = "continue"
+)
+
+// ParsePolicy converts a string into a Policy, an empty string means PolicyShutdown
+func ParsePolicy(p string) (Policy, error) {
+	switch Policy(p) {
+	case "", PolicyShutdown:
+		return PolicyShutdown, nil
+	case PolicyContinue:
+		return PolicyContinue, nil
+	default:
+		return "", fmt.Errorf("invalid failure policy %q, must be one of %s, %s", p, PolicyShutdown, PolicyContinue)
+	}
+}
+
+// New creates a shutdown coordinator
+func New() *Coordinator {
+	return &Coordinator{
+		policies: make(map[string]Policy),
+		running:  make(map[string]bool),
+		abort:    make(chan error, 1),
+	}
+}
+
+// SetPolicy sets the failure policy of a component
+func (c *Coordinator) SetPolicy(component string, policy Policy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policies[component] = policy
+}
+
+// Starting marks components as running, mark every component before starting the first one
+// so a component failing early does not leave the process looking idle
+func (c *Coordinator) Starting(components ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, component := range components {
+		c.running[component] = true
+	}
+}
+
+// Fail reports a component failure and applies the component's failure policy
+// When the last running component fails the process shuts down whatever its policy
+// It returns true when the failure shuts the process down
+func (c *Coordinator) Fail(component string, err error) bool {
+	c.mu.Lock()
+	policy, ok := c.policies[component]
+	last := c.running[component] && len(c.running) == 1
+	delete(c.running, component)
+	c.mu.Unlock()
+
+	if ok && policy == PolicyContinue && !last {
+		logging.Error(context.Background(), "Component failed, keep running the other components",
+			logging.NewField("component", component),
+			logging.NewField("error", err),
+		)
+		return false
+	}
+
+	c.Abort(fmt.Errorf("%s: %w", component, err))
+	return true
+}
+
+// Register adds a shutdown hook, timeout is the drain deadline of the hook
+func (c *Coordinator) Register(name string, timeout time.Duration, fn Func) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook{name: name, timeout: timeout, fn: fn})
+}
+
+// Abort requests a shutdown because a component failed, Wait returns the given error
+func (c *Coordinator) Abort(err error) {
+	c.aborted.Do(func() {
+		c.abort <- err
+	})
+}
+
+// Wait blocks until SIGINT or SIGTERM is received, ctx is done or Abort is called
+// It returns the error passed to Abort, or nil for a regular shutdown
+func (c *Coordinator) Wait(ctx context.Context) error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	select {
+	case s := <-sig:
+		logging.Info(ctx, "Shutdown signal received", logging.NewField("signal", s.String()))
+		return nil
+	case <-ctx.Done():
+		return nil
+	case err := <-c.abort:
+		logging.Error(ctx, "Shutting down after component failure", logging.NewField("error", err))
+		return err
+	}
+}
+
+// Shutdown runs every hook in reverse registration order, each within its own timeout
+// A hook that fails or does not finish in time is logged and the next hook still runs
+// It returns the errors of every hook that did not finish cleanly, joined
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	hooks := c.hooks
+	c.hooks = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := run(ctx, hooks[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func run(ctx context.Context, h hook) error {
+	start := time.Now()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			logging.Error(ctx, "Shutdown hook failed",
+				logging.NewField("hook", h.name),
+				logging.NewField("error", err),
+			)
+			return fmt.Errorf("%s: %w", h.name, err)
+		}
+		logging.Info(ctx, "Shutdown hook finished",
+			logging.NewField("hook", h.name),
+			logging.NewField("duration", time.Since(start)),
+		)
+		return nil
+	case <-ctx.Done():
+		logging.Warning(context.Background(), "Shutdown hook did not finish before its deadline",
+			logging.NewField("hook", h.name),
+			logging.NewField("timeout", h.timeout),
+		)
+		return fmt.Errorf("%s: did not finish: %w", h.name, ctx.Err())
+	}
+}
@@ -0,0 +1,120 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+)
+
+func init() {
+	logging.InitLogging(logging.NewSlogLogger(nil))
+}
+
+func TestShutdownRunsHooksInReverseOrder(t *testing.T) {
+	var order []string
+	sd := New()
+	for _, name := range []string{"adapters", "modules", "server"} {
+		sd.Register(name, time.Second, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	if err := sd.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shutdown: %v", err)
+	}
+
+	expected := []string{"server", "modules", "adapters"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+}
+
+func TestShutdownReportsUnfinishedHooks(t *testing.T) {
+	closed := false
+	sd := New()
+	sd.Register("adapters", time.Second, func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+	sd.Register("server", 10*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	err := sd.Shutdown(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if !closed {
+		t.Error("Expected adapters to be closed after a hook timed out")
+	}
+}
+
+func TestAbort(t *testing.T) {
+	sd := New()
+	abortErr := errors.New("listen failed")
+	sd.Abort(abortErr)
+	sd.Abort(errors.New("ignored"))
+
+	if err := sd.Wait(context.Background()); !errors.Is(err, abortErr) {
+		t.Errorf("Expected abort error, got %v", err)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected Policy
+		wantErr  bool
+	}{
+		{"", PolicyShutdown, false},
+		{"shutdown", PolicyShutdown, false},
+		{"continue", PolicyContinue, false},
+		{"restart", "", true},
+	}
+	for _, tt := range tests {
+		policy, err := ParsePolicy(tt.in)
+		if (err != nil) != tt.wantErr || policy != tt.expected {
+			t.Errorf("Expected %q (error %v) for %q, got %q (%v)", tt.expected, tt.wantErr, tt.in, policy, err)
+		}
+	}
+}
+
+func TestFail(t *testing.T) {
+	sd := New()
+	sd.SetPolicy("worker", PolicyContinue)
+	sd.SetPolicy("scheduler", PolicyContinue)
+	sd.Starting("http", "worker", "scheduler")
+
+	if sd.Fail("worker", errors.New("redis down")) {
+		t.Error("Expected a component with the continue policy to keep the process running")
+	}
+	if !sd.Fail("http", errors.New("listen failed")) {
+		t.Error("Expected a component without a policy to shut the process down")
+	}
+	if err := sd.Wait(context.Background()); err == nil || err.Error() != "http: listen failed" {
+		t.Errorf("Expected the http failure, got %v", err)
+	}
+}
+
+func TestFailLastRunning(t *testing.T) {
+	sd := New()
+	sd.SetPolicy("http", PolicyContinue)
+	sd.SetPolicy("worker", PolicyContinue)
+	sd.Starting("http", "worker")
+
+	if sd.Fail("worker", errors.New("redis down")) {
+		t.Error("Expected the process to keep running while http runs")
+	}
+	if !sd.Fail("http", errors.New("listen failed")) {
+		t.Error("Expected the failure of the last running component to shut the process down")
+	}
+	if err := sd.Wait(context.Background()); err == nil || err.Error() != "http: listen failed" {
+		t.Errorf("Expected the http failure, got %v", err)
+	}
+}
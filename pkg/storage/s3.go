@@ -23,7 +23,7 @@ func NewS3Client(cfg appcfg.S3) (*s3.Client, error) {
 	// Load konfigurasi AWS default dari environment, file config, dsb
 	awscfg, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithRegion(cfg.Region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(cfg.AccessKey, cfg.SecretKey, cfg.Session)),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(cfg.AccessKey.Value(), cfg.SecretKey.Value(), cfg.Session.Value())),
 	)
 	if err != nil {
 		logging.Error(context.Background(), fmt.Sprintf("unable to load SDK config, %v", err))
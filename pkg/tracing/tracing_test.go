@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetupStdout(t *testing.T) {
+	restore := Install(otel.GetTracerProvider())
+	defer restore()
+
+	var out bytes.Buffer
+	shutdown, err := Setup(context.Background(), Options{
+		ServiceName: "gostarter",
+		Exporter:    ExporterStdout,
+		SampleRatio: 1,
+		Output:      &out,
+	})
+	if err != nil {
+		t.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	ctx, span := Tracer().Start(context.Background(), "checkout")
+	if TraceID(ctx) == "" {
+		t.Error("Expected a trace ID in the span context")
+	}
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down tracing: %v", err)
+	}
+	if !strings.Contains(out.String(), `"Name":"checkout"`) {
+		t.Errorf("Expected the span to be exported, got %s", out.String())
+	}
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Options{Exporter: "jaeger"}); err == nil {
+		t.Error("Expected an error for an unknown exporter")
+	}
+}
+
+func TestTraceIDWithoutSpan(t *testing.T) {
+	if id := TraceID(context.Background()); id != "" {
+		t.Errorf("Expected no trace ID, got %s", id)
+	}
+}
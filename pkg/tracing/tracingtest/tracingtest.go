@@ -0,0 +1,35 @@
+// Package tracingtest records spans in memory so tests can assert on them
+package tracingtest
+
+import (
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/tracing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Capture installs a provider recording every span as soon as it ends
+// and restores the previous provider when the test ends
+func Capture(t testing.TB) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(tracing.Install(provider))
+	return exporter
+}
+
+// Span returns the ended span with the name, it fails the test when there is none
+func Span(t testing.TB, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	t.Helper()
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+		names = append(names, span.Name)
+	}
+	t.Fatalf("Expected a span %q, got %v", name, names)
+	return tracetest.SpanStub{}
+}
@@ -178,6 +178,32 @@ func parseTagToRules(tag string) []Rule {
 	return rules
 }
 
+// Luhn reports whether a string of digits passes the Luhn checksum used by card numbers
+// It returns false for an empty string or any character that is not a digit
+func Luhn(digits string) bool {
+	if digits == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		if digits[i] < '0' || digits[i] > '9' {
+			return false
+		}
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
 // helper: konversi berbagai tipe angka ke float64.
 func toFloat(v any) (float64, bool) {
 	switch n := v.(type) {
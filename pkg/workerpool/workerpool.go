@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"runtime/debug"
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/config"
+	"github.com/fatkulnurk/gostarter/pkg/logging"
 )
 
 type Priority int
@@ -119,7 +121,11 @@ func (wp *WorkerPool) executeWithRetry(workerID int, job Job) {
 			defer cancel()
 			defer func() {
 				if r := recover(); r != nil {
-					log.Printf("[Worker %d] PANIC: %v\n%s", workerID, r, debug.Stack())
+					logging.Error(wp.ctx, "Worker job panicked",
+						logging.NewField("worker", workerID),
+						logging.NewField("panic", r),
+						logging.NewField("stack", string(debug.Stack())),
+					)
 					err = fmt.Errorf("panic: %v", r)
 				}
 			}()
@@ -128,17 +134,21 @@ func (wp *WorkerPool) executeWithRetry(workerID int, job Job) {
 		}()
 
 		if err == nil {
-			log.Printf("[Worker %d] ✅ Job success (Attempt %d)", workerID, attempt)
+			logging.Debug(wp.ctx, "Worker job succeeded", logging.NewField("worker", workerID), logging.NewField("attempt", attempt))
 			return
 		}
 
-		log.Printf("[Worker %d] ❌ Job failed (Attempt %d): %v", workerID, attempt, err)
+		logging.Warning(wp.ctx, "Worker job failed",
+			logging.NewField("worker", workerID),
+			logging.NewField("attempt", attempt),
+			logging.NewField("error", err),
+		)
 
 		if attempt <= job.Retry {
 			time.Sleep(job.RetryDelay)
 		}
 	}
-	log.Printf("[Worker %d] 🔥 Job permanently failed after %d attempts", workerID, job.Retry+1)
+	logging.Error(wp.ctx, "Worker job permanently failed", logging.NewField("worker", workerID), logging.NewField("attempts", job.Retry+1))
 }
 
 // ScaleTo menyesuaikan jumlah worker aktif
@@ -158,12 +168,36 @@ func (wp *WorkerPool) ScaleTo(newCount int) {
 			wp.wg.Add(1)
 			go wp.worker(wp.workerCount + i)
 		}
-		log.Printf("⬆️ Scaled up to %d workers", wp.workerCount)
+		logging.Info(wp.ctx, "Worker pool scaled up", logging.NewField("workers", wp.workerCount))
 	} else {
-		log.Printf("⬇️ Scaled down to %d workers (pending stop after jobs)", wp.workerCount)
+		logging.Info(wp.ctx, "Worker pool scaled down, pending stop after jobs", logging.NewField("workers", wp.workerCount))
 	}
 }
 
+// ScaleWith scales the pool to size(cfg) whenever the given section of the watched config changes
+// A size that is not positive is ignored. It returns a function stopping the scaling
+func (wp *WorkerPool) ScaleWith(w *config.Watcher, section string, size func(*config.Config) int) (unsubscribe func()) {
+	return w.Subscribe(section, func(old, new *config.Config) {
+		if n := size(new); n > 0 && n != size(old) {
+			wp.ScaleTo(n)
+		}
+	})
+}
+
+// QueueLen returns the number of jobs waiting for a worker
+func (wp *WorkerPool) QueueLen() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return len(wp.jobQueue)
+}
+
+// WorkerCount returns the number of workers the pool is scaled to
+func (wp *WorkerPool) WorkerCount() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return wp.workerCount
+}
+
 func (wp *WorkerPool) Stop() {
 	wp.cancel()
 	wp.cond.Broadcast()
@@ -0,0 +1,178 @@
+package binding
+
+import (
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fatkulnurk/gostarter/pkg/apperror"
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TagSource chooses where a field is read from, like `bind:"query"`
+// Untagged fields are only read from the body, so a query string or route param can't set a field
+// the handler does not expect there
+const TagSource = "bind"
+
+const (
+	SourceBody   = "body"   // JSON, XML, urlencoded or multipart body, named by the `json`/`xml`/`form` tag
+	SourceQuery  = "query"  // query string, named by the `query` tag
+	SourcePath   = "path"   // route params, named by the `params` tag
+	SourceHeader = "header" // request headers, named by the `reqHeader` tag
+)
+
+var (
+	fileHeaderType  = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeadersType = reflect.TypeFor[[]*multipart.FileHeader]()
+)
+
+// Bind parses the request into a new T and validates it with its `validate` tags
+// A request that can't be parsed is a 400 and a request that fails validation a 422 listing every field
+//
+//	input, err := binding.Bind[CreateUserInput](c)
+//	if err != nil {
+//		return err
+//	}
+func Bind[T any](c *fiber.Ctx) (*T, error) {
+	out := new(T)
+	if err := Parse(c, out); err != nil {
+		return nil, err
+	}
+	if errs := validation.ValidateStruct(out); errs.HasErrors() {
+		return nil, apperror.Validation(errs)
+	}
+	return out, nil
+}
+
+// Parse fills the struct out points to from the body, query string, route params and headers
+func Parse(c *fiber.Ctx, out any) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return apperror.Newf(apperror.KindInternal, "bind target must be a pointer to a struct, got %T", out)
+	}
+	target = target.Elem()
+
+	sources, err := sourcesOf(target.Type())
+	if err != nil {
+		return err
+	}
+
+	// every source only decodes the fields it owns, so a query value can't fail a field read from the body
+	parsers := []struct {
+		source string
+		parse  func(any) error
+	}{
+		{SourceBody, func(v any) error { return parseBody(c, v) }},
+		{SourceQuery, c.QueryParser},
+		{SourcePath, c.ParamsParser},
+		{SourceHeader, c.ReqHeaderParser},
+	}
+	for _, p := range parsers {
+		owned := sources[p.source]
+		if len(owned.index) == 0 {
+			continue
+		}
+		v := reflect.New(owned.typ)
+		if err := p.parse(v.Interface()); err != nil {
+			return apperror.Wrap(err, apperror.KindBadRequest, "invalid request "+p.source)
+		}
+		if p.source == SourceBody {
+			if err := bindFiles(c, v.Elem()); err != nil {
+				return apperror.Wrap(err, apperror.KindBadRequest, "invalid request body")
+			}
+		}
+		for j, i := range owned.index {
+			target.Field(i).Set(v.Elem().Field(j))
+		}
+	}
+	return nil
+}
+
+// sourceFields is a struct type holding the fields read from one source, index maps them to the bound struct
+type sourceFields struct {
+	typ   reflect.Type
+	index []int
+}
+
+var sourceCache sync.Map // reflect.Type to map[string]sourceFields
+
+// sourcesOf splits the exported fields of t by the source they are read from
+func sourcesOf(t reflect.Type) (map[string]sourceFields, error) {
+	if cached, ok := sourceCache.Load(t); ok {
+		return cached.(map[string]sourceFields), nil
+	}
+
+	fields := make(map[string][]reflect.StructField)
+	index := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		source := field.Tag.Get(TagSource)
+		switch source {
+		case "":
+			source = SourceBody
+		case SourceBody, SourceQuery, SourcePath, SourceHeader:
+		default:
+			return nil, apperror.Newf(apperror.KindInternal, "field %s has unknown %s source %q", field.Name, TagSource, source)
+		}
+		fields[source] = append(fields[source], reflect.StructField{
+			Name:      field.Name,
+			Type:      field.Type,
+			Tag:       field.Tag,
+			Anonymous: field.Anonymous,
+		})
+		index[source] = append(index[source], i)
+	}
+
+	sources := make(map[string]sourceFields, len(fields))
+	for source := range fields {
+		sources[source] = sourceFields{typ: reflect.StructOf(fields[source]), index: index[source]}
+	}
+	sourceCache.Store(t, sources)
+	return sources, nil
+}
+
+// parseBody parses the body by its content type, an empty body leaves out untouched
+func parseBody(c *fiber.Ctx, out any) error {
+	if len(c.Body()) == 0 {
+		return nil
+	}
+	return c.BodyParser(out)
+}
+
+// bindFiles sets *multipart.FileHeader and []*multipart.FileHeader fields from the files of a multipart body
+func bindFiles(c *fiber.Ctx, v reflect.Value) error {
+	if !strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		return nil
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Type != fileHeaderType && field.Type != fileHeadersType {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		files := form.File[name]
+		if len(files) == 0 {
+			continue
+		}
+		if field.Type == fileHeaderType {
+			v.Field(i).Set(reflect.ValueOf(files[0]))
+		} else {
+			v.Field(i).Set(reflect.ValueOf(files))
+		}
+	}
+	return nil
+}
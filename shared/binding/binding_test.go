@@ -0,0 +1,117 @@
+package binding
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type updateUser struct {
+	ID      int                   `params:"id" json:"id" bind:"path"`
+	Name    string                `json:"name" form:"name" validate:"validateRequired"`
+	Email   string                `query:"email" json:"email" form:"email" validate:"email"`
+	Age     int                   `json:"age" form:"age"`
+	Notify  bool                  `query:"notify" json:"notify" bind:"query"`
+	TraceID string                `reqHeader:"X-Trace-Id" json:"trace_id" bind:"header"`
+	Avatar  *multipart.FileHeader `form:"avatar" json:"-"`
+}
+
+// bindApp binds every request to /users/:id and hands the result and error to check
+func bindApp(check func(*updateUser, error)) *fiber.App {
+	app := fiber.New()
+	app.Put("/users/:id", func(c *fiber.Ctx) error {
+		check(Bind[updateUser](c))
+		return nil
+	})
+	return app
+}
+
+func TestBindJSONQueryPathAndHeader(t *testing.T) {
+	var got *updateUser
+	app := bindApp(func(u *updateUser, err error) {
+		if err != nil {
+			t.Errorf("Failed to bind: %v", err)
+		}
+		got = u
+	})
+
+	// id and notify in the body are ignored, the path and the query string win
+	// the untagged email and age are only read from the body, a query value that does not even parse is ignored
+	body := `{"id": 99, "name": "Jane", "email": "jane@example.com", "age": 30, "notify": true}`
+	req := httptest.NewRequest("PUT", "/users/7?notify=false&email=admin@example.com&age=zz", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Trace-Id", "abc")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	want := updateUser{ID: 7, Name: "Jane", Email: "jane@example.com", Age: 30, Notify: false, TraceID: "abc"}
+	if got == nil || *got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	_ = w.WriteField("name", "Jane")
+	_ = w.WriteField("email", "jane@example.com")
+	part, _ := w.CreateFormFile("avatar", "avatar.png")
+	_, _ = part.Write([]byte("png"))
+	_ = w.Close()
+
+	var got *updateUser
+	app := bindApp(func(u *updateUser, err error) {
+		if err != nil {
+			t.Errorf("Failed to bind: %v", err)
+		}
+		got = u
+	})
+	req := httptest.NewRequest("PUT", "/users/7", &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if got == nil || got.Name != "Jane" || got.Avatar == nil || got.Avatar.Filename != "avatar.png" {
+		t.Errorf("Expected the form values and the avatar file, got %+v", got)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		body string
+		kind apperror.Kind
+	}{
+		{`{"name": `, apperror.KindBadRequest},
+		{`{"name": "", "email": "not-an-email"}`, apperror.KindValidation},
+	}
+	for _, tt := range tests {
+		var bindErr error
+		app := bindApp(func(_ *updateUser, err error) { bindErr = err })
+		req := httptest.NewRequest("PUT", "/users/7", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		if _, err := app.Test(req); err != nil {
+			t.Fatal(err)
+		}
+
+		if !apperror.Is(bindErr, tt.kind) {
+			t.Errorf("Expected a %s error for %s, got %v", tt.kind, tt.body, bindErr)
+		}
+	}
+
+	var bindErr error
+	app := bindApp(func(_ *updateUser, err error) { bindErr = err })
+	req := httptest.NewRequest("PUT", "/users/7", strings.NewReader(`{"email": "x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	_, _ = app.Test(req)
+	if fields := apperror.From(bindErr).Fields; len(fields.ForField("name")) == 0 || len(fields.ForField("email")) == 0 {
+		t.Errorf("Expected problems for name and email, got %v", fields)
+	}
+}
@@ -3,7 +3,9 @@ package infrastructure
 import (
 	"database/sql"
 
+	"github.com/fatkulnurk/gostarter/pkg/auth"
 	"github.com/fatkulnurk/gostarter/pkg/cache"
+	"github.com/fatkulnurk/gostarter/pkg/config"
 	"github.com/fatkulnurk/gostarter/pkg/mailer"
 	"github.com/fatkulnurk/gostarter/pkg/queue"
 	"github.com/fatkulnurk/gostarter/pkg/storage"
@@ -30,16 +32,20 @@ type Adapter struct {
 	Mailer  *mailer.Mailer
 	Queue   *queue.Queue
 	Storage *storage.Storage
+	Auth    auth.Authenticator
+	Watcher *config.Watcher
 }
 
 // NewAdapter creates a new Adapter instance with all required infrastructure dependencies
 // This function centralizes the creation of the adapter to ensure all required dependencies are provided
-func NewAdapter(db *DatabaseConnection, cache *cache.Cache, mailer *mailer.Mailer, queue *queue.Queue, storage *storage.Storage) *Adapter {
+func NewAdapter(db *DatabaseConnection, cache *cache.Cache, mailer *mailer.Mailer, queue *queue.Queue, storage *storage.Storage, authenticator auth.Authenticator, watcher *config.Watcher) *Adapter {
 	return &Adapter{
 		DB:      db,
 		Cache:   cache,
 		Mailer:  mailer,
 		Queue:   queue,
 		Storage: storage,
+		Auth:    authenticator,
+		Watcher: watcher,
 	}
 }
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/fatkulnurk/gostarter/pkg/apperror"
+	"github.com/fatkulnurk/gostarter/pkg/auth"
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Authenticate requires an authenticated caller on every route behind it, attach it to a module group:
+//
+//	group := m.Delivery.HTTP.Group("/api/v1/orders", middleware.Authenticate(m.Adapter.Auth))
+//
+// The principal is stored in the user context, read it with Principal or auth.FromContext
+// Requests without valid credentials get a 401 with the WWW-Authenticate challenge of the authenticator
+func Authenticate(authenticator auth.Authenticator) fiber.Handler {
+	return authenticate(authenticator, true)
+}
+
+// OptionalAuthenticate stores the principal when the request carries credentials and lets anonymous requests through,
+// invalid credentials still get a 401
+func OptionalAuthenticate(authenticator auth.Authenticator) fiber.Handler {
+	return authenticate(authenticator, false)
+}
+
+func authenticate(authenticator auth.Authenticator, required bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if authenticator == nil {
+			return apperror.Internal(errors.New("no authenticator configured"))
+		}
+
+		p, err := authenticator.Authenticate(c.UserContext(), func(key string) string { return c.Get(key) })
+		switch {
+		case errors.Is(err, auth.ErrNoCredentials) && !required:
+			return c.Next()
+		case errors.Is(err, auth.ErrNoCredentials), errors.Is(err, auth.ErrInvalidCredentials):
+			if ch, ok := authenticator.(auth.Challenger); ok && ch.Challenge() != "" {
+				c.Set(fiber.HeaderWWWAuthenticate, ch.Challenge())
+			}
+			logging.Debug(c.UserContext(), "Request not authenticated", logging.NewField("error", err.Error()))
+			return apperror.Unauthorized("authentication required")
+		case err != nil:
+			return apperror.Internal(err)
+		}
+
+		ctx := auth.NewContext(c.UserContext(), p)
+		c.SetUserContext(logging.WithFields(ctx, logging.NewField("principal_id", p.ID)))
+		return c.Next()
+	}
+}
+
+// RequireGroups only lets principals in at least one of the groups through and answers 403 otherwise
+// Put it after Authenticate, requests without a principal get a 401
+func RequireGroups(groups ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p, ok := auth.FromContext(c.UserContext())
+		if !ok {
+			return apperror.Unauthorized("authentication required")
+		}
+		if !p.InGroup(groups...) {
+			return apperror.Forbidden("insufficient permissions")
+		}
+		return c.Next()
+	}
+}
+
+// Principal returns the caller stored by Authenticate, nil for anonymous requests
+func Principal(c *fiber.Ctx) *auth.Principal {
+	p, _ := auth.FromContext(c.UserContext())
+	return p
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/auth"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// tokenAuthenticator accepts the tokens it maps to principals
+type tokenAuthenticator map[string]*auth.Principal
+
+func (a tokenAuthenticator) Authenticate(_ context.Context, header auth.Header) (*auth.Principal, error) {
+	token := header("Authorization")
+	switch {
+	case token == "":
+		return nil, auth.ErrNoCredentials
+	case token == "down":
+		return nil, errors.New("key store unreachable")
+	case a[token] == nil:
+		return nil, auth.ErrInvalidCredentials
+	}
+	return a[token], nil
+}
+
+func (a tokenAuthenticator) Challenge() string {
+	return "Bearer"
+}
+
+func newAuthApp() *fiber.App {
+	authenticator := tokenAuthenticator{
+		"admin": {ID: "1", Groups: []string{"admin"}},
+		"user":  {ID: "2"},
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(ErrorFormatJSON, false)})
+	whoami := func(c *fiber.Ctx) error {
+		if p := Principal(c); p != nil {
+			return c.SendString(p.ID)
+		}
+		return c.SendString("anonymous")
+	}
+	app.Get("/public", OptionalAuthenticate(authenticator), whoami)
+	api := app.Group("/api", Authenticate(authenticator))
+	api.Get("/me", whoami)
+	api.Get("/admin", RequireGroups("admin"), whoami)
+	return app
+}
+
+func TestAuthenticate(t *testing.T) {
+	app := newAuthApp()
+	tests := []struct {
+		path, token string
+		status      int
+		challenge   bool
+	}{
+		{path: "/api/me", token: "user", status: fiber.StatusOK},
+		{path: "/api/me", status: fiber.StatusUnauthorized, challenge: true},
+		{path: "/api/me", token: "forged", status: fiber.StatusUnauthorized, challenge: true},
+		{path: "/api/me", token: "down", status: fiber.StatusInternalServerError},
+		{path: "/api/admin", token: "admin", status: fiber.StatusOK},
+		{path: "/api/admin", token: "user", status: fiber.StatusForbidden},
+		{path: "/public", status: fiber.StatusOK},
+		{path: "/public", token: "user", status: fiber.StatusOK},
+		{path: "/public", token: "forged", status: fiber.StatusUnauthorized, challenge: true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(fiber.MethodGet, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", tt.token)
+		}
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != tt.status {
+			t.Errorf("%s with %q: expected status %d, got %d", tt.path, tt.token, tt.status, res.StatusCode)
+		}
+		if got := res.Header.Get(fiber.HeaderWWWAuthenticate) == "Bearer"; got != tt.challenge {
+			t.Errorf("%s with %q: expected challenge %v, got %q", tt.path, tt.token, tt.challenge, res.Header.Get(fiber.HeaderWWWAuthenticate))
+		}
+	}
+}
+
+func TestPrincipalInUserContext(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", Authenticate(tokenAuthenticator{"user": {ID: "2"}}), func(c *fiber.Ctx) error {
+		p, ok := auth.FromContext(c.UserContext())
+		if !ok {
+			return c.SendString("missing")
+		}
+		return c.SendString(p.ID)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "user")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer res.Body.Close()
+	body := make([]byte, 16)
+	n, _ := res.Body.Read(body)
+	if string(body[:n]) != "2" {
+		t.Errorf("Expected principal 2 in the user context, got %q", body[:n])
+	}
+}
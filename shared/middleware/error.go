@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fatkulnurk/gostarter/pkg/apperror"
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	ErrorFormatJSON    = "json"    // {"error": {"code", "message", "fields"}}
+	ErrorFormatProblem = "problem" // RFC 7807 application/problem+json
+)
+
+// ErrorResponse is the JSON envelope of an error response
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	Detail  string       `json:"detail,omitempty"` // the cause of an internal error, only in development
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details response, code and errors are extension members
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// ErrorHandler renders every error returned by a handler in the given format
+// Application errors keep their kind, validation.Errors become 422 responses listing each field
+// and fiber errors keep their status. Any other error is a 500 whose message is only shown when exposeInternal is set
+func ErrorHandler(format string, exposeInternal bool) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		appErr, status := toAppError(err)
+
+		if status >= http.StatusInternalServerError {
+			logging.Error(c.UserContext(), "Request failed",
+				logging.NewField("method", c.Method()),
+				logging.NewField("path", c.Path()),
+				logging.NewField("status", status),
+				logging.NewField("error", err),
+			)
+		}
+
+		code := appErr.Code
+		if code == "" {
+			code = string(appErr.Kind)
+		}
+		message := appErr.Message
+		detail := ""
+		if appErr.Kind == apperror.KindInternal && exposeInternal && appErr.Err != nil {
+			detail = appErr.Err.Error()
+		}
+		fields := make([]FieldError, 0, len(appErr.Fields))
+		for _, f := range appErr.Fields {
+			fields = append(fields, FieldError{Field: f.Field, Message: f.Message})
+		}
+
+		if format == ErrorFormatProblem {
+			if detail == "" {
+				detail = message
+			}
+			return c.Status(status).JSON(Problem{
+				Type:     "about:blank",
+				Title:    http.StatusText(status),
+				Status:   status,
+				Detail:   detail,
+				Instance: c.Path(), // without the query string, which may carry tokens or personal data
+				Code:     code,
+				Errors:   fields,
+			}, "application/problem+json")
+		}
+
+		return c.Status(status).JSON(ErrorResponse{Error: ErrorBody{
+			Code:    code,
+			Message: message,
+			Fields:  fields,
+			Detail:  detail,
+		}})
+	}
+}
+
+// toAppError converts fiber errors keeping their status and everything else with apperror.From
+func toAppError(err error) (*apperror.Error, int) {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		kind, ok := kinds[fiberErr.Code]
+		if !ok {
+			kind = apperror.KindBadRequest
+			if fiberErr.Code >= http.StatusInternalServerError {
+				kind = apperror.KindInternal
+			}
+		}
+		appErr := &apperror.Error{Kind: kind, Message: fiberErr.Message, Err: err}
+		if kind.Status() != fiberErr.Code {
+			// a status the kinds can't express, like 405 or 413
+			appErr.Code = strings.ReplaceAll(strings.ToLower(http.StatusText(fiberErr.Code)), " ", "_")
+		}
+		return appErr, fiberErr.Code
+	}
+
+	appErr := apperror.From(err)
+	return appErr, appErr.Status()
+}
+
+var kinds = map[int]apperror.Kind{
+	http.StatusBadRequest:          apperror.KindBadRequest,
+	http.StatusUnauthorized:        apperror.KindUnauthorized,
+	http.StatusForbidden:           apperror.KindForbidden,
+	http.StatusNotFound:            apperror.KindNotFound,
+	http.StatusConflict:            apperror.KindConflict,
+	http.StatusUnprocessableEntity: apperror.KindValidation,
+	http.StatusTooManyRequests:     apperror.KindTooManyRequests,
+	http.StatusServiceUnavailable:  apperror.KindUnavailable,
+	http.StatusInternalServerError: apperror.KindInternal,
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/apperror"
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func init() {
+	logging.InitLogging(logging.NewSlogLogger(nil))
+}
+
+func newErrorApp(format string, exposeInternal bool) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(format, exposeInternal)})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return apperror.NotFound("user not found")
+	})
+	app.Post("/invalid", func(c *fiber.Ctx) error {
+		return validation.Errors{{Field: "email", Message: "is not a valid email"}}
+	})
+	app.Get("/broken", func(c *fiber.Ctx) error {
+		return errors.New("dial tcp: connection refused")
+	})
+	return app
+}
+
+func request(t *testing.T, app *fiber.App, method, path string) (int, string, map[string]any) {
+	t.Helper()
+	res, err := app.Test(httptest.NewRequest(method, path, nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("Expected a JSON body, got %v", err)
+	}
+	return res.StatusCode, res.Header.Get(fiber.HeaderContentType), body
+}
+
+func TestErrorHandlerJSON(t *testing.T) {
+	app := newErrorApp(ErrorFormatJSON, false)
+
+	tests := []struct {
+		method, path string
+		status       int
+		code         string
+	}{
+		{"GET", "/missing", 404, "not_found"},
+		{"POST", "/invalid", 422, "validation"},
+		{"GET", "/broken", 500, "internal"},
+		{"GET", "/no-route", 404, "not_found"},
+		{"PUT", "/missing", 405, "method_not_allowed"},
+	}
+	for _, tt := range tests {
+		status, _, body := request(t, app, tt.method, tt.path)
+		if status != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.status, status)
+		}
+		envelope, _ := body["error"].(map[string]any)
+		if envelope["code"] != tt.code {
+			t.Errorf("%s %s: expected code %s, got %v", tt.method, tt.path, tt.code, envelope["code"])
+		}
+	}
+
+	_, _, body := request(t, app, "POST", "/invalid")
+	fields, _ := body["error"].(map[string]any)["fields"].([]any)
+	if len(fields) != 1 || fields[0].(map[string]any)["field"] != "email" {
+		t.Errorf("Expected the email field error, got %v", fields)
+	}
+}
+
+func TestErrorHandlerHidesInternalErrors(t *testing.T) {
+	_, _, body := request(t, newErrorApp(ErrorFormatJSON, false), "GET", "/broken")
+	envelope := body["error"].(map[string]any)
+	if envelope["message"] != "internal server error" || envelope["detail"] != nil {
+		t.Errorf("Expected the cause to be hidden, got %v", envelope)
+	}
+
+	_, _, body = request(t, newErrorApp(ErrorFormatJSON, true), "GET", "/broken")
+	if detail := body["error"].(map[string]any)["detail"]; detail != "dial tcp: connection refused" {
+		t.Errorf("Expected the cause in development, got %v", detail)
+	}
+}
+
+func TestErrorHandlerProblem(t *testing.T) {
+	status, contentType, body := request(t, newErrorApp(ErrorFormatProblem, false), "POST", "/invalid?token=secret")
+	if status != 422 || contentType != "application/problem+json" {
+		t.Errorf("Expected 422 application/problem+json, got %d %s", status, contentType)
+	}
+	if body["status"] != float64(422) || body["title"] != "Unprocessable Entity" || body["instance"] != "/invalid" {
+		t.Errorf("Expected problem details, got %v", body)
+	}
+	if errs, _ := body["errors"].([]any); len(errs) != 1 {
+		t.Errorf("Expected one field error, got %v", body["errors"])
+	}
+}
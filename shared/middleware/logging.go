@@ -1,47 +1,111 @@
 package middleware
 
 import (
-	"context"
-	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+
 	"github.com/gofiber/fiber/v2"
 )
 
+// AccessLogConfig samples the access log, a value N logs 1 in N requests and 0 logs none
+// A route setting, keyed by the route pattern like /users/:id, wins over the status class setting
+type AccessLogConfig struct {
+	Routes map[string]int
+	Status map[string]int // keyed by 1xx, 2xx, 3xx, 4xx or 5xx
+}
+
+// AccessLog logs every request it does not sample out and counts the dropped ones
+type AccessLog struct {
+	cfg atomic.Pointer[AccessLogConfig]
+
+	mu      sync.Mutex
+	counts  map[string]uint64
+	dropped atomic.Uint64
+}
+
+// NewAccessLog creates an access log with the given sampling
+func NewAccessLog(cfg AccessLogConfig) *AccessLog {
+	a := &AccessLog{counts: make(map[string]uint64)}
+	a.cfg.Store(&cfg)
+	return a
+}
+
+// SetConfig replaces the sampling of a running access log, like after a config reload
+func (a *AccessLog) SetConfig(cfg AccessLogConfig) {
+	a.cfg.Store(&cfg)
+}
+
+// LoggingMiddleware logs every request with the fields attached to its user context, like the request ID
 func LoggingMiddleware() fiber.Handler {
+	return NewAccessLog(AccessLogConfig{}).Handler()
+}
+
+// Dropped returns how many requests were not logged because of sampling
+func (a *AccessLog) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Handler logs every request with the fields attached to its user context, like the request ID
+func (a *AccessLog) Handler() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Record start time
 		start := time.Now()
 
-		// Defer logging until after request is processed
-		defer func() {
-			// Calculate request duration
-			duration := time.Since(start)
-
-			// Collect request details
-			statusCode := c.Response().StatusCode()
-			method := c.Method()
-			path := c.Path()
-			ip := c.IP()
-			userAgent := string(c.Request().Header.UserAgent())
-
-			// Check if there's a mismatch between actual status and what's being logged
-			if c.Response().StatusCode() != statusCode {
-				logging.Warning(context.Background(), "Status code mismatch", logging.NewField("actual_status", c.Response().StatusCode()))
-			}
-
-			// Log request information
-			logging.Info(context.Background(), "Incoming request",
-				logging.NewField("method", method),
-				logging.NewField("path", path),
-				logging.NewField("status", statusCode),
-				logging.NewField("ip", ip),
-				logging.NewField("user_agent", userAgent),
-				logging.NewField("latency", duration),
-			)
-		}()
-
 		// Process request
-		return c.Next()
+		err := c.Next()
+
+		// the error is rendered by the app's error handler after this middleware, take the status it will be rendered with
+		statusCode := c.Response().StatusCode()
+		if err != nil {
+			_, statusCode = toAppError(err)
+		}
+		if !a.sample(c.Route().Path, statusCode) {
+			return err
+		}
+
+		// Log request information
+		logging.Info(c.UserContext(), "Incoming request",
+			logging.NewField("method", c.Method()),
+			logging.NewField("path", c.Path()),
+			logging.NewField("status", statusCode),
+			logging.NewField("ip", c.IP()),
+			logging.NewField("user_agent", string(c.Request().Header.UserAgent())),
+			logging.NewField("latency", time.Since(start)),
+		)
+		return err
+	}
+}
+
+// sample reports whether the request is logged, the Nth request of a route or status class is kept
+func (a *AccessLog) sample(route string, status int) bool {
+	cfg := a.cfg.Load()
+	key := "route:" + route
+	every, ok := cfg.Routes[route]
+	if !ok {
+		class := fmt.Sprintf("%dxx", status/100)
+		key = "status:" + class
+		every, ok = cfg.Status[class]
+	}
+	if !ok || every == 1 {
+		return true
+	}
+	if every <= 0 {
+		a.dropped.Add(1)
+		return false
+	}
+
+	a.mu.Lock()
+	n := a.counts[key]
+	a.counts[key] = n + 1
+	a.mu.Unlock()
+
+	if n%uint64(every) == 0 {
+		return true
 	}
+	a.dropped.Add(1)
+	return false
 }
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging/logtest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAccessLogSampling(t *testing.T) {
+	logs := logtest.Capture(t)
+	access := NewAccessLog(AccessLogConfig{
+		Routes: map[string]int{"/ping": 0},
+		Status: map[string]int{"2xx": 2},
+	})
+	app := fiber.New()
+	app.Use(access.Handler())
+	app.Get("/ping", func(c *fiber.Ctx) error { return c.SendString("pong") })
+	app.Get("/users/:id", func(c *fiber.Ctx) error { return c.SendString("user") })
+	app.Get("/fail", func(c *fiber.Ctx) error { return fiber.ErrBadGateway })
+
+	requests := []string{"/ping", "/ping", "/users/1", "/users/2", "/users/3", "/users/4", "/fail"}
+	for _, path := range requests {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// both pings, and every other 2xx; the 502 is a 5xx, which is not sampled
+	if access.Dropped() != 4 {
+		t.Errorf("Expected 4 dropped entries, got %d", access.Dropped())
+	}
+	logs.ExpectField(t, "Incoming request", "status", fiber.StatusBadGateway)
+
+	// a returned error counts as its own status class, not as the 2xx of the response before rendering
+	access = NewAccessLog(AccessLogConfig{Status: map[string]int{"2xx": 0}})
+	app = fiber.New()
+	app.Use(access.Handler())
+	app.Get("/fail", func(c *fiber.Ctx) error { return fiber.ErrBadGateway })
+	logs.Reset()
+	res, err := app.Test(httptest.NewRequest("GET", "/fail", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusBadGateway || access.Dropped() != 0 {
+		t.Errorf("Expected the 502 to be logged, got status %d and %d dropped", res.StatusCode, access.Dropped())
+	}
+	logs.ExpectField(t, "Incoming request", "status", fiber.StatusBadGateway)
+}
+
+func TestAccessLogSetConfig(t *testing.T) {
+	logtest.Capture(t)
+	access := NewAccessLog(AccessLogConfig{})
+	app := fiber.New()
+	app.Use(access.Handler())
+	app.Get("/ping", func(c *fiber.Ctx) error { return c.SendString("pong") })
+
+	// a running access log samples with the replaced config, like after a reload
+	access.SetConfig(AccessLogConfig{Routes: map[string]int{"/ping": 0}})
+	for range 3 {
+		if _, err := app.Test(httptest.NewRequest("GET", "/ping", nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if access.Dropped() != 3 {
+		t.Errorf("Expected 3 dropped entries after the config change, got %d", access.Dropped())
+	}
+}
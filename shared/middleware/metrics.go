@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fatkulnurk/gostarter/pkg/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unmatchedRoute labels requests that matched no route, so unknown paths don't create new series
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the latency of every request by method, route pattern and status
+// Register it first: it renders errors with the app's error handler to observe the final status
+func MetricsMiddleware(m *metrics.Metrics) fiber.Handler {
+	var routes routePatterns
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		if err := c.Next(); err != nil {
+			if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		m.ObserveHTTP(c.Method(), routes.of(c), c.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
+
+// routePatterns resolves the route pattern a request matched, like /users/:id
+type routePatterns struct {
+	once   sync.Once
+	routes map[string]bool
+}
+
+// of returns the pattern of the route that handled the request, unmatchedRoute when no route matched
+// Call it after c.Next, routes are registered before the app serves its first request
+func (p *routePatterns) of(c *fiber.Ctx) string {
+	p.once.Do(func() {
+		p.routes = make(map[string]bool)
+		for _, r := range c.App().GetRoutes(true) {
+			p.routes[r.Method+" "+r.Path] = true
+		}
+	})
+
+	// a request matching no route ends on a middleware, which is not a route of its own
+	route := c.Route()
+	if !p.routes[route.Method+" "+route.Path] {
+		return unmatchedRoute
+	}
+	return route.Path
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := metrics.New()
+	app := fiber.New()
+	app.Use(MetricsMiddleware(m))
+	app.Get("/users/:id", func(c *fiber.Ctx) error { return c.SendString("user") })
+	app.Get("/fail", func(c *fiber.Ctx) error { return fiber.ErrBadGateway })
+
+	for _, path := range []string{"/users/1", "/users/2", "/fail", "/missing/1", "/missing/2"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`http_request_duration_seconds_count{method="GET",route="/users/:id",status="200"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="/fail",status="502"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the exposition to contain %s", want)
+		}
+	}
+}
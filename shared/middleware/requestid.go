@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/fatkulnurk/gostarter/pkg/requestid"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestIDMiddleware accepts the X-Request-ID of the client or generates one, echoes it in the response
+// and stores it in the user context, so logs and enqueued tasks of the request carry it
+func RequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set(requestid.Header, id)
+		c.SetUserContext(requestid.NewContext(c.UserContext(), id))
+		return c.Next()
+	}
+}
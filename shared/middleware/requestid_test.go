@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/requestid"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(requestid.FromContext(c.UserContext()))
+	})
+
+	tests := []struct {
+		name, incoming string
+		kept           bool
+	}{
+		{"client id", "abc-123", true},
+		{"missing id", "", false},
+		{"id with spaces", "abc 123", false},
+		{"id too long", strings.Repeat("a", requestid.MaxLength+1), false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.incoming != "" {
+			req.Header.Set(requestid.Header, tt.incoming)
+		}
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := make([]byte, 256)
+		n, _ := res.Body.Read(body)
+		id := res.Header.Get(requestid.Header)
+
+		if id == "" || string(body[:n]) != id {
+			t.Errorf("%s: expected the context and response to share the id, got %q and %q", tt.name, body[:n], id)
+		}
+		if (id == tt.incoming) != tt.kept {
+			t.Errorf("%s: expected kept %v, got id %q", tt.name, tt.kept, id)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fatkulnurk/gostarter/pkg/logging"
+	"github.com/fatkulnurk/gostarter/pkg/tracing/tracingtest"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	spans := tracingtest.Capture(t)
+
+	var traceField any
+	app := fiber.New()
+	app.Use(TracingMiddleware())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		for _, f := range logging.ContextFields(c.UserContext()) {
+			if f.Key == "trace_id" {
+				traceField = f.Value
+			}
+		}
+		return c.SendString("user")
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error { return fiber.ErrBadGateway })
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Test(httptest.NewRequest("GET", "/fail", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	span := tracingtest.Span(t, spans, "GET /users/:id")
+	if span.SpanKind != trace.SpanKindServer {
+		t.Errorf("Expected a server span, got %s", span.SpanKind)
+	}
+	if got := span.Parent.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the trace of the traceparent header, got %s", got)
+	}
+	if traceField != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the trace ID in the log fields, got %v", traceField)
+	}
+
+	failed := tracingtest.Span(t, spans, "GET /fail")
+	if failed.Status.Code != codes.Error {
+		t.Errorf("Expected an error status for a 502, got %s", failed.Status.Code)
+	}
+}